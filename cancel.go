@@ -0,0 +1,260 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCancelCondition is the error with which a Group is canceled by CancelWhen.
+var ErrCancelCondition = errors.New("pgroup: cancel condition met")
+
+// Cancel cancels all tasks in the Group explicitly, recording err as the error of the Group (if no task has failed yet).
+// err is also the cause of the cancellation (see context.Cause). nil err means context.Canceled.
+//
+// In collect-all mode, err is added to the collected errors, and tasks are canceled as well.
+func (pg *Group) Cancel(err error) {
+	if err == nil {
+		err = context.Canceled
+	}
+	pg.fail(err)
+	if pg.collectAll {
+		pg.cancelSelf(err)
+	}
+}
+
+// CancelWhen starts a watcher which polls pred every interval, and cancels the Group with ErrCancelCondition as soon as pred returns true.
+// It integrates external control signals (e.g. feature flags) without a task dedicated to watching them.
+//
+// The watcher stops when the Group is canceled for any reason, including the completion of Wait().
+// It panics if pred is nil or interval <= 0.
+func (pg *Group) CancelWhen(pred func() bool, interval time.Duration) {
+	if pred == nil || interval <= 0 {
+		panic("pgroup: invalid cancel condition")
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-pg.ctx.Done():
+				return
+			case <-ticker.C:
+				if pred() {
+					pg.Cancel(ErrCancelCondition)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// BindContext attaches ctx as an additional source of cancellation: when ctx is done, the Group is canceled by Cancel with the cause of ctx (see context.Cause).
+// Unlike the parent context, ctx can be bound after the Group is created, e.g. to tie the Group to the lifecycle of a request.
+//
+// The binding is released when Wait completes.
+func (pg *Group) BindContext(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() {
+		pg.Cancel(context.Cause(ctx))
+	})
+	pg.OnDone(func() { stop() })
+}
+
+// LinkCancel links the cancellation of a and b both ways: when either of them is canceled due to a failure (or Cancel, or the cancellation of its parent context), the other is canceled by Cancel with the same cause.
+// The normal completion of Wait of either Group doesn't affect the other.
+//
+// It coordinates multi-Group pipelines, e.g. a Group of producers and a Group of consumers.
+func LinkCancel(a, b *Group) {
+	a.linkCancelTo(b)
+	b.linkCancelTo(a)
+}
+
+func (pg *Group) linkCancelTo(other *Group) {
+	pg.OnCancelCause(func(cause error) {
+		if pg.canceledOnFinish.Load() {
+			return
+		}
+		if other.ctx.Err() == nil {
+			other.canceledByLink.Store(true)
+		}
+		other.Cancel(cause)
+	})
+}
+
+// WaitBoth links the cancellation of a and b (see LinkCancel), then waits on both concurrently, and returns their errors joined into an error (see errors.Join).
+// If the error of one Group is just the one propagated from the other, it's reported only once, as the error of the Group where it originated (prefixed by its name, if any).
+func WaitBoth(a, b *Group) error {
+	LinkCancel(a, b)
+
+	errB := make(chan error, 1)
+	go func() { errB <- b.Wait() }()
+	ea, eb := a.Wait(), <-errB
+
+	if ea == nil || eb == nil {
+		return errors.Join(ea, eb)
+	}
+	// errors of Groups are prefixed by names of Groups differently, so they are compared without the prefixes.
+	ra, rb := unwrapGroupError(ea), unwrapGroupError(eb)
+	aHasB, bHasA := errors.Is(ra, rb), errors.Is(rb, ra)
+	switch {
+	case aHasB && bHasA:
+		// the same error; report it as the one of the originating Group.
+		if a.canceledByLink.Load() {
+			return eb
+		}
+		return ea
+	case aHasB:
+		return ea
+	case bHasA:
+		return eb
+	}
+	return errors.Join(ea, eb)
+}
+
+// WithCancelErrorMapper makes the Group rewrite errors of tasks which were aborted by the cancellation due to a failure of another task (or Cancel) with mapper, before they reach any hook, logs, or Promises.
+// For instance, it can rewrite "context canceled" into a sentinel like ErrSiblingFailed, to make logs of large fan-outs less confusing.
+//
+// mapper is applied only to errors which are context errors (context.Canceled or context.DeadlineExceeded) returned after the Group has been canceled due to a reason inside it; the originating failure, and errors due to the cancellation of the parent context are left untouched.
+func WithCancelErrorMapper(mapper func(error) error) Option {
+	return func(pg *Group) {
+		pg.cancelErrMapper = mapper
+	}
+}
+
+func (pg *Group) mapCancelErr(err error) error {
+	if pg.cancelErrMapper == nil || err == nil || !pg.selfCanceled.Load() {
+		return err
+	}
+	if !isContextErr(err) {
+		return err
+	}
+	return pg.cancelErrMapper(err)
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// ErrFirstSuccess is the cause of the cancellation (see context.Cause) of tasks which lost a race: the rest of tasks of a Group in first-success-wins mode when a task has succeeded, and losers in FirstMatch and RaceEither.
+var ErrFirstSuccess = errors.New("pgroup: another task succeeded first")
+
+// WithFirstSuccessWins makes the Group complete as soon as any task launched by Go (or other functions returning a Promise) succeeds: the rest of tasks are canceled, and Wait returns nil.
+// The Promise of the winning task holds its value.
+//
+// It is handy for "query many, use the first good answer". Errors of tasks after the first success are ignored, but if a task failed before any success, the Group fails as usual.
+func WithFirstSuccessWins() Option {
+	return func(pg *Group) {
+		pg.firstSuccessWins = true
+	}
+}
+
+// WithSuccessGrace makes the Group run in first-success-wins mode (see WithFirstSuccessWins), but gives the rest of tasks d of extra time to finish after the first success, before canceling them.
+// It balances using the fastest result and letting others complete cheaply (e.g. for cache warming): results of tasks finished within the grace period are available in their Promises.
+//
+// As in first-success-wins mode, errors of tasks after the first success are ignored and Wait returns nil.
+func WithSuccessGrace(d time.Duration) Option {
+	return func(pg *Group) {
+		pg.firstSuccessWins = true
+		pg.successGrace = d
+	}
+}
+
+// win completes the Group successfully, unless it has already failed.
+func (pg *Group) win() {
+	if pg.collectAll {
+		if pg.errs.err(nil) == nil && pg.won.CompareAndSwap(false, true) {
+			pg.cancelAfterGrace()
+		}
+		return
+	}
+	pg.errOnce.Do(func() {
+		pg.won.Store(true)
+		pg.cancelAfterGrace()
+	})
+}
+
+// cancelAfterGrace cancels the rest of tasks after the first success, when the grace period set by WithSuccessGrace has elapsed.
+func (pg *Group) cancelAfterGrace() {
+	if pg.successGrace <= 0 {
+		pg.cancelSelf(ErrFirstSuccess)
+		return
+	}
+	t := time.AfterFunc(pg.successGrace, func() { pg.cancelSelf(ErrFirstSuccess) })
+	pg.OnDone(func() { t.Stop() })
+}
+
+// Token is a lightweight cancellation signal of a single task, returned from GoToken.
+// Unlike deriving a child context, it doesn't allocate anything per task other than the Token itself.
+type Token struct {
+	canceled atomic.Int32
+}
+
+// Cancel signals the task to stop. It doesn't affect other tasks in the Group.
+func (t *Token) Cancel() {
+	t.canceled.Store(1)
+}
+
+// Canceled reports whether Cancel has been called.
+func (t *Token) Canceled() bool {
+	return t.canceled.Load() != 0
+}
+
+// GoToken is like Go, but it also returns a Token to cancel just the launched task. The Token is passed to f as well.
+//
+// Cancellation by the Token is cooperative: f must check Token.Canceled periodically, and return promptly once it's canceled.
+// The context passed to f is not affected by the Token; it's still canceled along with the Group.
+// It panics if f is nil.
+func GoToken[T any](pg *Group, f func(ctx context.Context, tok *Token) (T, error)) (*Promise[T], *Token) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	tok := &Token{}
+	p := Go(pg, func(ctx context.Context) (T, error) {
+		return f(ctx, tok)
+	})
+	return p, tok
+}
+
+// GoWithCancelValue is like Go, but if the task is canceled (i.e. it returned a context error after its context was done), the Promise resolves successfully with def instead of failing.
+// It's useful in partial-result aggregation (e.g. CollectAll), where a canceled task should contribute a sentinel.
+//
+// The cancellation is not reported to the Group as an error of the task; the failure which caused the cancellation is still returned from Wait.
+// It panics if f is nil.
+func GoWithCancelValue[T any](pg *Group, def T, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	return Go(pg, func(ctx context.Context) (T, error) {
+		v, err := f(ctx)
+		if err != nil && ctx.Err() != nil && isContextErr(err) {
+			return def, nil
+		}
+		return v, err
+	})
+}
+
+// RequestStop asks tasks in the Group to stop gracefully: tasks can check it by StopRequested, then wrap up and return (possibly partial) results voluntarily.
+// Unlike the cancellation of the Group's context, which tasks treat as an abort, it doesn't interrupt anything by itself. It enables two-phase stops: RequestStop first, then Cancel if tasks don't stop in time.
+//
+// The request is also visible to tasks of Groups created in tasks of the Group.
+func (pg *Group) RequestStop() {
+	pg.stopRequested.Store(true)
+}
+
+// StopRequested reports whether RequestStop has been called on the Group which the task that received ctx belongs to, or on any of its outer Groups.
+// It reports false if ctx is not derived from a context passed to a task in a Group.
+func StopRequested(ctx context.Context) bool {
+	pg, ok := FromContext(ctx)
+	if !ok {
+		return false
+	}
+	for ; pg != nil; pg = pg.outer {
+		if pg.stopRequested.Load() {
+			return true
+		}
+	}
+	return false
+}
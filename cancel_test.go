@@ -0,0 +1,384 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCancel(t *testing.T) {
+	errExp := errors.New("stop!")
+
+	pg := New()
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+
+	pg.Cancel(errExp)
+
+	if err := pg.Wait(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cause := context.Cause(pg.ctx); cause != errExp {
+		t.Fatalf("unexpected cause: %v", cause)
+	}
+}
+
+func TestCancelWhen_invalid(t *testing.T) {
+	pg := New()
+	defer pg.Wait()
+
+	assertPanics(t, "pgroup: invalid cancel condition", func() { pg.CancelWhen(func() bool { return false }, 0) })
+	assertPanics(t, "pgroup: invalid cancel condition", func() { pg.CancelWhen(func() bool { return false }, -time.Second) })
+	assertPanics(t, "pgroup: invalid cancel condition", func() { pg.CancelWhen(nil, time.Second) })
+}
+
+func TestCancelWhen(t *testing.T) {
+	var flag atomic.Bool
+
+	pg := New()
+	pg.CancelWhen(flag.Load, 10*time.Millisecond)
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+
+	time.AfterFunc(100*time.Millisecond, func() { flag.Store(true) })
+
+	start := time.Now()
+	if err := pg.Wait(); err != ErrCancelCondition {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("the Group should be canceled soon after the condition is met")
+	}
+}
+
+func TestBindContext(t *testing.T) {
+	errReq := errors.New("request aborted")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	pg := New()
+	pg.BindContext(ctx)
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+
+	time.AfterFunc(10*time.Millisecond, func() { cancel(errReq) })
+
+	start := time.Now()
+	if err := pg.Wait(); err != errReq {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("the Group should be canceled soon after the bound context is done")
+	}
+}
+
+func TestBindContext_afterWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pg := New()
+	pg.BindContext(ctx)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// canceling the bound context after Wait doesn't affect the result
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if err := pg.waitErr(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithCancelErrorMapper(t *testing.T) {
+	errSiblingFailed := errors.New("sibling failed")
+	errExp := errors.New("error!")
+
+	var l eventLog
+	pg := New(
+		WithCollectAll(),
+		WithCancelErrorMapper(func(err error) error { return errSiblingFailed }),
+	)
+	ForEachResult(pg, func(_ int, err error) { l.add(err.Error()) })
+
+	p := Go(pg, delayedResultTask(time.Second, func() (int, error) { return 0, nil }))
+	Go(pg, delayedResultTask(50*time.Millisecond, func() (int, error) { return 0, errExp }))
+	time.AfterFunc(100*time.Millisecond, func() { pg.Cancel(errExp) })
+
+	err := pg.Wait()
+	if !errors.Is(err, errExp) || !errors.Is(err, errSiblingFailed) || errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.err != errSiblingFailed {
+		t.Fatalf("error of the aborted task should be mapped: %v", p.err)
+	}
+	// the originating failure is not mapped.
+	assertEvents(t, []string{"error!", "sibling failed"}, l.get())
+}
+
+func TestWithCancelErrorMapper_parentCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	pg := WithContext(ctx, WithCancelErrorMapper(func(err error) error { return errors.New("mapped") }))
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+
+	if err := pg.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("errors due to the parent context should not be mapped: %v", err)
+	}
+}
+
+func TestWithFirstSuccessWins(t *testing.T) {
+	pg := New(WithFirstSuccessWins())
+
+	slow := Go(pg, delayedResultTask(time.Second, func() (string, error) { return "slow", nil }))
+	fast := Go(pg, delayedResultTask(50*time.Millisecond, func() (string, error) { return "fast", nil }))
+	Go(pg, delayedResultTask(time.Second, func() (string, error) { return "", errors.New("too late error") }))
+
+	start := time.Now()
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("other tasks should be canceled on the first success")
+	}
+	if fast.Get() != "fast" {
+		t.Fatalf("unexpected result of the winner: %v", fast.Get())
+	}
+	if slow.err != context.Canceled || context.Cause(pg.ctx) != ErrFirstSuccess {
+		t.Fatalf("the loser should be canceled by the first success: %v, %v", slow.err, context.Cause(pg.ctx))
+	}
+
+	// a failure before any success fails the Group.
+	errExp := errors.New("error!")
+	pg = New(WithFirstSuccessWins())
+	Go(pg, delayedResultTask(50*time.Millisecond, func() (string, error) { return "", errExp }))
+	Go(pg, delayedResultTask(100*time.Millisecond, func() (string, error) { return "ok", nil }))
+	if err := pg.Wait(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGoToken(t *testing.T) {
+	pg := New()
+
+	p, tok := GoToken(pg, func(ctx context.Context, tok *Token) (int, error) {
+		n := 0
+		for !tok.Canceled() {
+			if err := Sleep(ctx, time.Millisecond); err != nil {
+				return n, err
+			}
+			n++
+		}
+		return n, nil
+	})
+	other := Go(pg, delayedResultTask(30*time.Millisecond, func() (string, error) { return "ok", nil }))
+
+	time.AfterFunc(10*time.Millisecond, tok.Cancel)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() == 0 {
+		t.Fatal("task should have run until the cancellation")
+	}
+	if other.Get() != "ok" {
+		t.Fatal("cancellation by the Token should not affect other tasks")
+	}
+}
+
+func TestRequestStop(t *testing.T) {
+	pg := New()
+
+	p := Go(pg, func(ctx context.Context) ([]int, error) {
+		var partial []int
+		for i := 0; !StopRequested(ctx); i++ {
+			if err := Sleep(ctx, time.Millisecond); err != nil {
+				return nil, err
+			}
+			partial = append(partial, i)
+		}
+		return partial, nil
+	})
+	nested := Go(pg, func(ctx context.Context) (bool, error) {
+		inner := WithContext(ctx)
+		p := Go(inner, func(ctx context.Context) (bool, error) {
+			for !StopRequested(ctx) {
+				if err := Sleep(ctx, time.Millisecond); err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		err := inner.Wait()
+		return p.Get(), err
+	})
+
+	time.AfterFunc(20*time.Millisecond, pg.RequestStop)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Get()) == 0 {
+		t.Fatal("task should return partial results")
+	}
+	if !nested.Get() {
+		t.Fatal("the request should be visible to tasks of nested Groups")
+	}
+	if StopRequested(context.Background()) {
+		t.Fatal("StopRequested should be false outside Groups")
+	}
+}
+
+func TestWithSuccessGrace(t *testing.T) {
+	pg := New(WithSuccessGrace(50 * time.Millisecond))
+
+	fast := Go(pg, delayedResultTask(5*time.Millisecond, func() (string, error) { return "fast", nil }))
+	warm := Go(pg, delayedResultTask(20*time.Millisecond, func() (string, error) { return "warm", nil }))
+	slow := Go(pg, delayedResultTask(time.Second, func() (string, error) { return "slow", nil }))
+	Go(pg, delayedResultTask(30*time.Millisecond, func() (string, error) { return "", errors.New("ignored") }))
+
+	start := time.Now()
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("the rest of tasks should be canceled after the grace period")
+	}
+	if fast.Get() != "fast" || warm.Get() != "warm" {
+		t.Fatalf("tasks finished within the grace period should succeed: %v, %v", fast.Get(), warm.err)
+	}
+	if slow.err != context.Canceled || context.Cause(pg.ctx) != ErrFirstSuccess {
+		t.Fatalf("the slow task should be canceled after the grace period: %v, %v", slow.err, context.Cause(pg.ctx))
+	}
+}
+
+// readCtx is a fake blocking I/O operation aware of ctx, like operations of net.Dialer: it reads from conn until ctx is done, then unblocks by closing conn.
+func readCtx(ctx context.Context, conn net.Conn) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	_, err := conn.Read(make([]byte, 1))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func TestCancel_releasesBlockingIO(t *testing.T) {
+	errExp := errors.New("sibling failed")
+
+	pg := New()
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	blocked := Go(pg, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, readCtx(ctx, conn)
+	})
+	GoAndForget(pg, delayedTask(10*time.Millisecond, func() error { return errExp }))
+
+	done := make(chan error, 1)
+	go func() { done <- pg.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != errExp {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocking I/O should be released by the failure of a sibling")
+	}
+	if !errors.Is(blocked.err, context.Canceled) {
+		t.Fatalf("blocked task should be canceled: %v", blocked.err)
+	}
+}
+
+func TestWait_cancelsBeforeReturn(t *testing.T) {
+	pg := New()
+
+	var ctxErr error
+	GoAndForget(pg, func(ctx context.Context) error {
+		// done hooks are run in Wait.
+		pg.OnDone(func() { ctxErr = ctx.Err() })
+		return nil
+	})
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctxErr != context.Canceled {
+		t.Fatalf("context of tasks should be canceled before Wait returns: %v", ctxErr)
+	}
+}
+
+func TestGoWithCancelValue(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New()
+	canceled := GoWithCancelValue(pg, -1, delayedResultTask(time.Second, func() (int, error) { return 1, nil }))
+	done := GoWithCancelValue(pg, -1, func(context.Context) (int, error) { return 2, nil })
+	failed := GoWithCancelValue(pg, -1, delayedResultTask(10*time.Millisecond, func() (int, error) { return 0, errExp }))
+
+	res, err := CollectAll(pg, []*Promise[int]{canceled, done, failed})
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res[0] != -1 || res[1] != 2 || res[2] != 0 {
+		t.Fatalf("unexpected results: %v", res)
+	}
+	if failed.err != errExp {
+		t.Fatalf("errors other than cancellation should be kept: %v", failed.err)
+	}
+}
+
+func TestWaitBoth(t *testing.T) {
+	errExp := errors.New("consumer failed")
+
+	producers, consumers := New(), New()
+	p := Go(producers, delayedResultTask(time.Second, func() (int, error) { return 1, nil }))
+	GoAndForget(consumers, delayedTask(10*time.Millisecond, func() error { return errExp }))
+
+	start := time.Now()
+	if err := WaitBoth(producers, consumers); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond || !errors.Is(p.err, context.Canceled) {
+		t.Fatalf("failure of a Group should cancel the other: %v", p.err)
+	}
+
+	// independent failures are joined
+	errA, errB := errors.New("a"), errors.New("b")
+	a, b := New(WithCollectAll()), New(WithCollectAll())
+	GoAndForget(a, func(context.Context) error { return errA })
+	GoAndForget(b, func(context.Context) error { return errB })
+	if err := WaitBoth(a, b); !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitBoth_named(t *testing.T) {
+	errExp := errors.New("boom")
+
+	a := WithContextNamed(context.Background(), "a")
+	b := WithContextNamed(context.Background(), "b")
+	GoAndForget(a, delayedTask(time.Second, func() error { return nil }))
+	GoAndForget(b, delayedTask(10*time.Millisecond, func() error { return errExp }))
+
+	err := WaitBoth(a, b)
+	if !errors.Is(err, errExp) || err.Error() != "b: boom" {
+		t.Fatalf("propagated error should be reported only once, by the originating Group: %v", err)
+	}
+}
+
+func TestLinkCancel_completion(t *testing.T) {
+	a, b := New(), New()
+	LinkCancel(a, b)
+
+	GoAndForget(a, func(context.Context) error { return nil })
+	if err := a.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p := Go(b, delayedResultTask(20*time.Millisecond, func() (int, error) { return 1, nil }))
+	if err := b.Wait(); err != nil || p.Get() != 1 {
+		t.Fatalf("completion of a Group should not cancel the other: %v", err)
+	}
+}
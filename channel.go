@@ -0,0 +1,173 @@
+package pgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// FanIn merges values from all the given channels into a single channel.
+// It launches a task per input channel on the Group, each copies values from the input to the merged output.
+//
+// The output channel is closed when all input channels are closed, or the Group is canceled.
+// Note that the Group's Wait() doesn't return until either of them happens.
+func FanIn[T any](pg *Group, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		ch := ch
+		pg.launch(nil, func(ctx context.Context) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case v, ok := <-ch:
+					if !ok {
+						return nil
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return nil
+					}
+				}
+			}
+		}, func(error) { wg.Done() })
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// IndexedResult is a result of a task launched by GoIndexed, with the index of the task in the submission order.
+type IndexedResult[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// GoIndexed launches each given function in a new goroutine, and returns a channel which emits their results in the completion order.
+// Each result has the index of the function in fs, so that the results can be reassembled in the submission order later.
+//
+// The index is also available in the task via TaskInfo.
+// The channel is buffered enough to hold all results, and closed after all results are emitted.
+// Errors from the functions are also reported to the Group as usual.
+// It panics if any of fs is nil.
+func GoIndexed[T any](pg *Group, fs ...func(ctx context.Context) (T, error)) <-chan IndexedResult[T] {
+	for _, f := range fs {
+		if f == nil {
+			panic(nilTaskMsg)
+		}
+	}
+
+	out := make(chan IndexedResult[T], len(fs))
+	var wg sync.WaitGroup
+	wg.Add(len(fs))
+
+	for i, f := range fs {
+		i, f := i, f
+		var v T
+		pg.launch(&taskInfo{index: i}, func(ctx context.Context) error {
+			var err error
+			v, err = f(ctx)
+			return err
+		}, func(err error) {
+			if err != nil {
+				var zero T
+				v = zero
+			}
+			out <- IndexedResult[T]{Index: i, Value: v, Err: err}
+			wg.Done()
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// BatchStream delivers results of tasks launched by its Go method in batches. It is created by GoStreamBatched.
+type BatchStream[T any] struct {
+	pg   *Group
+	size int
+
+	mu    sync.Mutex
+	batch []T
+	out   chan []T
+}
+
+// GoStreamBatched returns a BatchStream, which delivers values of successful tasks launched by BatchStream.Go in slices of up to batchSize values, in the completion order.
+// A batch is delivered as soon as it gets full, and the last partial batch is delivered when the Group's Wait() finishes; the channel is closed after that.
+//
+// It amortizes the overhead of channel sends for high-throughput fan-outs with a huge number of tiny results.
+// Sends to the channel block, so the caller should keep receiving from it until it's closed; the Group's Wait() doesn't return until the last batch is received.
+// Errors from tasks are reported to the Group as usual, and not delivered to the channel.
+// It panics if batchSize <= 0.
+func GoStreamBatched[T any](pg *Group, batchSize int) *BatchStream[T] {
+	if batchSize <= 0 {
+		panic("pgroup: non-positive batch size")
+	}
+	s := &BatchStream[T]{
+		pg:   pg,
+		size: batchSize,
+		out:  make(chan []T),
+	}
+	pg.OnDone(s.close)
+	return s
+}
+
+// Go launches the given function in a new goroutine, and delivers its value to the stream if it succeeds.
+//
+// It panics if f is nil.
+func (s *BatchStream[T]) Go(f func(ctx context.Context) (T, error)) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	var v T
+	s.pg.launch(nil, func(ctx context.Context) error {
+		var err error
+		v, err = f(ctx)
+		return err
+	}, func(err error) {
+		if err == nil {
+			s.add(v)
+		}
+	})
+}
+
+// C returns the channel which emits batches of values.
+func (s *BatchStream[T]) C() <-chan []T {
+	return s.out
+}
+
+// add appends v to the current batch, and delivers the batch if it gets full.
+func (s *BatchStream[T]) add(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, v)
+	if len(s.batch) >= s.size {
+		s.out <- s.batch
+		s.batch = nil
+	}
+}
+
+// close delivers the last partial batch (if any), and closes the channel.
+func (s *BatchStream[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.batch) > 0 {
+		s.out <- s.batch
+		s.batch = nil
+	}
+	close(s.out)
+}
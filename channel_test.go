@@ -0,0 +1,133 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestFanIn(t *testing.T) {
+	gen := func(vs ...int) <-chan int {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for _, v := range vs {
+				ch <- v
+			}
+		}()
+		return ch
+	}
+
+	pg := New()
+
+	var got []int
+	for v := range FanIn(pg, gen(1, 2, 3), gen(4, 5), gen()) {
+		got = append(got, v)
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Ints(got)
+	want := []int{1, 2, 3, 4, 5}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected result (want: %v, got: %v)", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected result (want: %v, got: %v)", want, got)
+		}
+	}
+}
+
+func TestFanIn_canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	pg := WithContext(ctx)
+
+	// input channels which are never closed.
+	out := FanIn(pg, make(chan int), make(chan int))
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("no value is expected")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("output channel should be closed on cancellation")
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGoIndexed(t *testing.T) {
+	errExp := errors.New("error!")
+	tasks := []func(context.Context) (string, error){
+		delayedResultTask(300*time.Millisecond, func() (string, error) { return "a", nil }),
+		delayedResultTask(100*time.Millisecond, func() (string, error) { return "b", nil }),
+		delayedResultTask(200*time.Millisecond, func() (string, error) { return "", errExp }),
+	}
+
+	pg := WithContext(context.Background())
+
+	var order []int
+	got := make([]IndexedResult[string], len(tasks))
+	for r := range GoIndexed(pg, tasks...) {
+		order = append(order, r.Index)
+		got[r.Index] = r
+	}
+	if err := pg.Wait(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// task 0 is canceled by the failure of task 2.
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 0 {
+		t.Fatalf("unexpected completion order: %v", order)
+	}
+	if got[1].Value != "b" || got[1].Err != nil {
+		t.Fatalf("unexpected result of task 1: %+v", got[1])
+	}
+	if got[2].Err != errExp {
+		t.Fatalf("unexpected result of task 2: %+v", got[2])
+	}
+	if got[0].Err != context.Canceled {
+		t.Fatalf("unexpected result of task 0: %+v", got[0])
+	}
+}
+
+func TestGoStreamBatched(t *testing.T) {
+	pg := New()
+	s := GoStreamBatched[int](pg, 3)
+
+	for i := 0; i < 7; i++ {
+		i := i
+		s.Go(func(context.Context) (int, error) { return i, nil })
+	}
+
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- pg.Wait() }()
+
+	var sizes []int
+	var got []int
+	for b := range s.C() {
+		sizes = append(sizes, len(b))
+		got = append(got, b...)
+	}
+	if err := <-waitErr; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Fatalf("unexpected batch sizes: %v", sizes)
+	}
+	sort.Ints(got)
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("unexpected values: %v", got)
+		}
+	}
+}
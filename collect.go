@@ -0,0 +1,326 @@
+package pgroup
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// RunMap applies f to each of inputs concurrently with at most limit tasks running at once, and returns the results in the same order as inputs.
+// limit <= 0 means no limit.
+//
+// It is a shorthand for creating a Group, setting the limit, launching tasks and Wait()-ing on the Group.
+// If any of tasks failed, it returns the error (the first one) and nil results.
+func RunMap[In, Out any](ctx context.Context, limit int, inputs []In, f func(context.Context, In) (Out, error)) ([]Out, error) {
+	pg := WithContext(ctx)
+	if limit > 0 {
+		pg.SetLimit(limit)
+	}
+
+	ps := make([]*Promise[Out], len(inputs))
+	for i, in := range inputs {
+		in := in
+		ps[i] = Go(pg, func(ctx context.Context) (Out, error) { return f(ctx, in) })
+	}
+	if err := pg.Wait(); err != nil {
+		return nil, err
+	}
+
+	res := make([]Out, len(ps))
+	for i, p := range ps {
+		res[i] = p.Get()
+	}
+	return res, nil
+}
+
+// CollectAll waits on the Group, then returns the results of ps in the same order as ps, along with the error of the Group.
+//
+// The returned slice always has the same length as ps, even if the Group failed: it holds the values of tasks which have succeeded, and zero values at indices of tasks which failed or didn't finish, so that indices stay aligned with ps.
+func CollectAll[T any](pg *Group, ps []*Promise[T]) ([]T, error) {
+	err := pg.Wait()
+
+	res := make([]T, len(ps))
+	for i, p := range ps {
+		if v, ok := p.succeeded(); ok {
+			res[i] = v
+		}
+	}
+	return res, err
+}
+
+// CollectSorted waits on the Group, then returns the values of succeeded ones of ps sorted in ascending order by key, along with the error of the Group.
+// Values of failed (or unfinished) tasks are excluded. The sort is stable, so values with equal keys keep the order of ps.
+func CollectSorted[T any, K cmp.Ordered](pg *Group, ps []*Promise[T], key func(T) K) ([]T, error) {
+	err := pg.Wait()
+
+	res := make([]T, 0, len(ps))
+	for _, p := range ps {
+		if v, ok := p.succeeded(); ok {
+			res = append(res, v)
+		}
+	}
+	slices.SortStableFunc(res, func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	})
+	return res, err
+}
+
+// Map applies f to each of inputs concurrently on the Group, then waits on the Group and returns the results in the same order as inputs (see CollectAll).
+//
+// Like CollectAll, the returned slice always has the same length as inputs, even if the Group failed.
+func Map[In, Out any](pg *Group, inputs []In, f func(context.Context, In) (Out, error)) ([]Out, error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	ps := make([]*Promise[Out], len(inputs))
+	for i, in := range inputs {
+		in := in
+		ps[i] = Go(pg, func(ctx context.Context) (Out, error) { return f(ctx, in) })
+	}
+	return CollectAll(pg, ps)
+}
+
+// Filter returns results of successful tasks among ps which satisfy keep, in the same order as ps.
+// Failed or unresolved Promises are skipped.
+//
+// It should be called after the relevant Group's Wait() returned.
+func Filter[T any](ps []*Promise[T], keep func(T) bool) []T {
+	res := make([]T, 0, len(ps))
+	for _, p := range ps {
+		if v, ok := p.succeeded(); ok && keep(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Wait2 waits on the Group, then returns the results of the two Promises.
+// If the Group failed, it returns zero values and the error.
+func Wait2[A, B any](pg *Group, pa *Promise[A], pb *Promise[B]) (A, B, error) {
+	if err := pg.Wait(); err != nil {
+		var (
+			za A
+			zb B
+		)
+		return za, zb, err
+	}
+	return pa.Get(), pb.Get(), nil
+}
+
+// Wait3 waits on the Group, then returns the results of the three Promises.
+// If the Group failed, it returns zero values and the error.
+func Wait3[A, B, C any](pg *Group, pa *Promise[A], pb *Promise[B], pc *Promise[C]) (A, B, C, error) {
+	if err := pg.Wait(); err != nil {
+		var (
+			za A
+			zb B
+			zc C
+		)
+		return za, zb, zc, err
+	}
+	return pa.Get(), pb.Get(), pc.Get(), nil
+}
+
+// Wait4 waits on the Group, then returns the results of the four Promises.
+// If the Group failed, it returns zero values and the error.
+func Wait4[A, B, C, D any](pg *Group, pa *Promise[A], pb *Promise[B], pc *Promise[C], pd *Promise[D]) (A, B, C, D, error) {
+	if err := pg.Wait(); err != nil {
+		var (
+			za A
+			zb B
+			zc C
+			zd D
+		)
+		return za, zb, zc, zd, err
+	}
+	return pa.Get(), pb.Get(), pc.Get(), pd.Get(), nil
+}
+
+// MapUnique is like Map, but runs f only once for each unique input, which saves work for expensive idempotent operations on inputs with duplicates.
+// It returns the results keyed by inputs, along with the error of the Group. Inputs whose tasks failed (or didn't finish) are absent from the map.
+func MapUnique[In comparable, Out any](pg *Group, inputs []In, f func(context.Context, In) (Out, error)) (map[In]Out, error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	seen := make(map[In]struct{}, len(inputs))
+	uniq := make([]In, 0, len(inputs))
+	for _, in := range inputs {
+		if _, ok := seen[in]; !ok {
+			seen[in] = struct{}{}
+			uniq = append(uniq, in)
+		}
+	}
+
+	ps := make([]*Promise[Out], len(uniq))
+	for i, in := range uniq {
+		in := in
+		ps[i] = Go(pg, func(ctx context.Context) (Out, error) { return f(ctx, in) })
+	}
+	err := pg.Wait()
+
+	res := make(map[In]Out, len(uniq))
+	for i, p := range ps {
+		if v, ok := p.succeeded(); ok {
+			res[uniq[i]] = v
+		}
+	}
+	return res, err
+}
+
+// MapKeyedResults launches each of tasks on the Group, then waits on the Group and returns the complete per-key outcome: values of succeeded tasks, and errors of failed ones.
+// Each key of tasks is in exactly one of the returned maps.
+//
+// Failures of the tasks don't fail the Group nor cancel other tasks, like GoSideEffect.
+// If Wait returned before some of the tasks finished (see WithWaitTimeout), their keys are in the error map with the error returned from Wait.
+// It panics if any of tasks is nil.
+func MapKeyedResults[K comparable, V any](pg *Group, tasks map[K]func(context.Context) (V, error)) (map[K]V, map[K]error) {
+	for _, f := range tasks {
+		if f == nil {
+			panic(nilTaskMsg)
+		}
+	}
+
+	ps := make(map[K]*Promise[V], len(tasks))
+	for k, f := range tasks {
+		f := f
+		ps[k] = Go(pg, func(ctx context.Context) (V, error) {
+			v, err := f(ctx)
+			if err != nil {
+				return v, &isolatedError{err: err}
+			}
+			return v, nil
+		})
+	}
+	waitErr := pg.Wait()
+
+	vals := make(map[K]V, len(ps))
+	errs := make(map[K]error)
+	for k, p := range ps {
+		select {
+		case <-p.done:
+		default:
+			errs[k] = waitErr
+			continue
+		}
+		if v, ok := p.succeeded(); ok {
+			vals[k] = v
+		} else {
+			errs[k] = p.err
+		}
+	}
+	return vals, errs
+}
+
+// GoRangeN launches n tasks running f with indices 0 to n-1, like a parallel for-loop, and returns their Promises aligned with the indices.
+// Combined with SetLimit, it makes a bounded parallel loop.
+//
+// The index is also available in the task via TaskInfo.
+// It panics if f is nil.
+func GoRangeN[T any](pg *Group, n int, f func(ctx context.Context, i int) (T, error)) []*Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	ps := make([]*Promise[T], n)
+	for i := 0; i < n; i++ {
+		i, p := i, newPromise[T](pg)
+		ps[i] = p
+
+		task, settle := promiseTask(p, func(ctx context.Context) (T, error) { return f(ctx, i) })
+		pg.launch(&taskInfo{index: i}, task, settle)
+	}
+	return ps
+}
+
+// Waves runs tasks on the Group in waves: the tasks of each wave are launched concurrently, and the next wave starts only after all tasks of the wave have completed successfully.
+// It stops launching waves as soon as a wave fails, and waits on the Group (see Wait) in the end.
+//
+// It returns Promises of the launched waves aligned with waves, along with the error of the Group. Waves not launched due to a failure are excluded.
+func Waves[T any](pg *Group, waves [][]func(context.Context) (T, error)) ([][]*Promise[T], error) {
+	for _, wave := range waves {
+		for _, f := range wave {
+			if f == nil {
+				panic(nilTaskMsg)
+			}
+		}
+	}
+
+	res := make([][]*Promise[T], 0, len(waves))
+	for _, wave := range waves {
+		ps := make([]*Promise[T], len(wave))
+		for i, f := range wave {
+			ps[i] = Go(pg, f)
+		}
+		res = append(res, ps)
+
+		if err := pg.WaitNoCancel(); err != nil {
+			break
+		}
+	}
+	return res, pg.Wait()
+}
+
+// Gather waits for all of ps to resolve, and returns the values of succeeded ones in the order of ps, along with the errors of failed ones joined into an error (see errors.Join).
+// The error is nil if all of ps succeeded.
+//
+// Unlike CollectAll, it doesn't Wait on a Group, so it works with arbitrary Promises, even ones from different Groups or created by NewPromise.
+func Gather[T any](ps ...*Promise[T]) ([]T, error) {
+	var (
+		res  []T
+		errs []error
+	)
+	for _, p := range ps {
+		<-p.done
+		if p.err != nil {
+			errs = append(errs, p.err)
+			continue
+		}
+		res = append(res, p.res)
+	}
+	return res, errors.Join(errs...)
+}
+
+// ScatterGather runs tasks concurrently, and waits for them up to d. A failure of a task doesn't cancel other tasks.
+// It returns the results and the errors of tasks aligned with tasks: for each i, either results[i] holds the value of the succeeded task, or errs[i] holds its error.
+// errs[i] is context.DeadlineExceeded if the task didn't finish in time; such tasks are canceled with the cause context.DeadlineExceeded (see context.Cause) and left running in the background.
+//
+// Both slices always have the same length as tasks. errs[i] is nil for succeeded tasks.
+func ScatterGather[T any](ctx context.Context, d time.Duration, tasks ...func(context.Context) (T, error)) ([]T, []error) {
+	for _, f := range tasks {
+		if f == nil {
+			panic(nilTaskMsg)
+		}
+	}
+
+	pg := WithContext(ctx, WithCollectAll())
+	ps := make([]*Promise[T], len(tasks))
+	for i, f := range tasks {
+		ps[i] = Go(pg, f)
+	}
+	timedOut := !pg.waitWithin(d)
+
+	results := make([]T, len(ps))
+	errs := make([]error, len(ps))
+	for i, p := range ps {
+		select {
+		case <-p.done:
+			if p.err != nil {
+				errs[i] = p.err
+			} else {
+				results[i] = p.res
+			}
+		default:
+			errs[i] = context.DeadlineExceeded
+		}
+	}
+
+	if timedOut {
+		pg.Cancel(context.DeadlineExceeded)
+		go pg.Wait()
+	}
+	return results, errs
+}
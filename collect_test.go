@@ -0,0 +1,351 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunMap(t *testing.T) {
+	inputs := []int{1, 2, 3, 4, 5}
+
+	res, err := RunMap(context.Background(), 2, inputs, func(ctx context.Context, n int) (int, error) {
+		time.Sleep(time.Duration(5-n) * 10 * time.Millisecond)
+		return n * n, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []int{1, 4, 9, 16, 25}
+	for i := range want {
+		if res[i] != want[i] {
+			t.Fatalf("unexpected result (want: %v, got: %v)", want, res)
+		}
+	}
+}
+
+func TestRunMap_err(t *testing.T) {
+	errExp := errors.New("error!")
+
+	res, err := RunMap(context.Background(), 0, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, errExp
+		}
+		return n, nil
+	})
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != nil {
+		t.Fatalf("results should be nil on error: %v", res)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	ps := make([]*Promise[int], 0, 6)
+	for i := 0; i < 6; i++ {
+		i := i
+		ps = append(ps, Go(pg, func(ctx context.Context) (int, error) {
+			if i == 4 {
+				return 0, errors.New("error!")
+			}
+			return i, nil
+		}))
+	}
+	_ = pg.Wait()
+
+	got := Filter(ps, func(n int) bool { return n%2 == 0 })
+	want := []int{0, 2}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected result (want: %v, got: %v)", want, got)
+	}
+}
+
+func TestWait2(t *testing.T) {
+	pg := New()
+	pa := Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 42, nil }))
+	pb := Go(pg, delayedResultTask(100*time.Millisecond, func() (string, error) { return "result", nil }))
+
+	a, b, err := Wait2(pg, pa, pb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a != 42 || b != "result" {
+		t.Fatalf("unexpected results: %v, %v", a, b)
+	}
+
+	errExp := errors.New("error!")
+	pg = New()
+	pa = Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 42, nil }))
+	pb = Go(pg, delayedResultTask(100*time.Millisecond, func() (string, error) { return "", errExp }))
+	pc := Go(pg, delayedResultTask(100*time.Millisecond, func() (bool, error) { return true, nil }))
+
+	a, b, c, err := Wait3(pg, pa, pb, pc)
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a != 0 || b != "" || c {
+		t.Fatalf("results should be zero values on error: %v, %v, %v", a, b, c)
+	}
+}
+
+func TestMap(t *testing.T) {
+	pg := New()
+
+	res, err := Map(pg, []string{"a", "bb", "ccc"}, func(ctx context.Context, s string) (int, error) { return len(s), nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 3 || res[0] != 1 || res[1] != 2 || res[2] != 3 {
+		t.Fatalf("unexpected result: %v", res)
+	}
+}
+
+func TestCollectAll_partialFailure(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New()
+	ps := []*Promise[int]{
+		Go(pg, delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil })),
+		Go(pg, delayedResultTask(50*time.Millisecond, func() (int, error) { return 0, errExp })),
+		Go(pg, delayedResultTask(time.Second, func() (int, error) { return 3, nil })),
+		Go(pg, delayedResultTask(20*time.Millisecond, func() (int, error) { return 4, nil })),
+	}
+
+	res, err := CollectAll(pg, ps)
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// indices are aligned with promises even on failure.
+	want := []int{1, 0, 0, 4}
+	if len(res) != len(want) {
+		t.Fatalf("unexpected result (want: %v, got: %v)", want, res)
+	}
+	for i := range want {
+		if res[i] != want[i] {
+			t.Fatalf("unexpected result (want: %v, got: %v)", want, res)
+		}
+	}
+}
+
+func TestCollectSorted(t *testing.T) {
+	type user struct {
+		name string
+		age  int
+	}
+
+	pg := New(WithCollectAll())
+	errExp := errors.New("error!")
+	ps := []*Promise[user]{
+		Go(pg, delayedResultTask(time.Millisecond, func() (user, error) { return user{"a", 30}, nil })),
+		Go(pg, delayedResultTask(time.Millisecond, func() (user, error) { return user{}, errExp })),
+		Go(pg, delayedResultTask(time.Millisecond, func() (user, error) { return user{"c", 20}, nil })),
+		Go(pg, delayedResultTask(time.Millisecond, func() (user, error) { return user{"d", 30}, nil })),
+	}
+
+	res, err := CollectSorted(pg, ps, func(u user) int { return u.age })
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 3 || res[0].name != "c" || res[1].name != "a" || res[2].name != "d" {
+		t.Fatalf("unexpected results: %v", res)
+	}
+}
+
+func TestMapUnique(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	errExp := errors.New("error!")
+	var calls atomic.Int32
+	res, err := MapUnique(pg, []string{"a", "b", "a", "x", "b", "a"}, func(ctx context.Context, s string) (string, error) {
+		calls.Add(1)
+		if s == "x" {
+			return "", errExp
+		}
+		return strings.ToUpper(s), nil
+	})
+
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("f should be called once per unique input: %d", n)
+	}
+	if len(res) != 2 || res["a"] != "A" || res["b"] != "B" {
+		t.Fatalf("unexpected results: %v", res)
+	}
+}
+
+func TestMapKeyedResults(t *testing.T) {
+	pg := New()
+
+	errExp := errors.New("error!")
+	vals, errs := MapKeyedResults(pg, map[string]func(context.Context) (int, error){
+		"a": delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }),
+		"b": func(context.Context) (int, error) { return 0, errExp },
+		"c": func(ctx context.Context) (int, error) {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+				return 3, nil
+			}
+		},
+	})
+
+	if len(vals) != 2 || vals["a"] != 1 || vals["c"] != 3 {
+		t.Fatalf("a failure should not cancel other tasks: %v", vals)
+	}
+	if len(errs) != 1 || errs["b"] != errExp {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("failures of the tasks should not fail the Group: %v", err)
+	}
+
+	// tasks left behind by the watchdog of Wait
+	release := make(chan struct{})
+	defer close(release)
+	pg = New(WithWaitTimeout(20 * time.Millisecond))
+	vals, errs = MapKeyedResults(pg, map[string]func(context.Context) (int, error){
+		"a": func(context.Context) (int, error) { return 1, nil },
+		"b": func(context.Context) (int, error) {
+			<-release
+			return 2, nil
+		},
+	})
+	if len(vals) != 1 || vals["a"] != 1 {
+		t.Fatalf("unexpected values: %v", vals)
+	}
+	if len(errs) != 1 || !errors.Is(errs["b"], ErrWaitTimeout) {
+		t.Fatalf("unfinished task should be reported with the error of Wait: %v", errs)
+	}
+}
+
+func TestGoRangeN(t *testing.T) {
+	pg := New()
+	pg.SetLimit(2)
+
+	ps := GoRangeN(pg, 5, func(ctx context.Context, i int) (int, error) {
+		if _, idx, ok := TaskInfo(ctx); !ok || idx != i {
+			return 0, fmt.Errorf("unexpected task index: %d", idx)
+		}
+		time.Sleep(time.Duration(5-i) * time.Millisecond)
+		return i * i, nil
+	})
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ps) != 5 {
+		t.Fatalf("unexpected number of Promises: %d", len(ps))
+	}
+	for i, p := range ps {
+		if p.Get() != i*i {
+			t.Fatalf("unexpected result at %d: %v", i, p.Get())
+		}
+	}
+}
+
+func TestWaves(t *testing.T) {
+	l := &eventLog{}
+	task := func(name string, err error) func(context.Context) (string, error) {
+		return delayedResultTask(time.Millisecond, func() (string, error) {
+			l.add(name)
+			return name, err
+		})
+	}
+
+	pg := New()
+	ps, err := Waves(pg, [][]func(context.Context) (string, error){
+		{task("a1", nil), task("a2", nil)},
+		{task("b1", nil)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ps) != 2 || len(ps[0]) != 2 || len(ps[1]) != 1 || ps[1][0].Get() != "b1" {
+		t.Fatalf("unexpected Promises: %v", ps)
+	}
+	if got := l.get(); len(got) != 3 || got[2] != "b1" {
+		t.Fatalf("the second wave should start after the first one: %v", got)
+	}
+
+	// a failed wave stops the following waves
+	errExp := errors.New("error!")
+	l = &eventLog{}
+	pg = New()
+	ps, err = Waves(pg, [][]func(context.Context) (string, error){
+		{task("a1", nil)},
+		{task("b1", errExp)},
+		{task("c1", nil)},
+	})
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ps) != 2 || ps[0][0].Get() != "a1" {
+		t.Fatalf("unexpected Promises: %v", ps)
+	}
+	assertEvents(t, []string{"a1", "b1"}, l.get())
+}
+
+func TestGather(t *testing.T) {
+	err1, err2 := errors.New("error 1"), errors.New("error 2")
+
+	pg1 := New(WithCollectAll())
+	p1 := Go(pg1, delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }))
+	p2 := Go(pg1, delayedResultTask(10*time.Millisecond, func() (int, error) { return 0, err1 }))
+
+	pg2 := New(WithCollectAll())
+	p3 := Go(pg2, delayedResultTask(20*time.Millisecond, func() (int, error) { return 0, err2 }))
+
+	p4, resolve := NewPromise[int]()
+	time.AfterFunc(10*time.Millisecond, func() { resolve(4, nil) })
+
+	res, err := Gather(p1, p2, p3, p4)
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 2 || res[0] != 1 || res[1] != 4 {
+		t.Fatalf("unexpected results: %v", res)
+	}
+
+	if res, err := Gather(p1, p4); err != nil || len(res) != 2 {
+		t.Fatalf("unexpected result: %v, %v", res, err)
+	}
+}
+
+func TestScatterGather(t *testing.T) {
+	errExp := errors.New("error!")
+
+	start := time.Now()
+	res, errs := ScatterGather(context.Background(), 50*time.Millisecond,
+		delayedResultTask(10*time.Millisecond, func() (string, error) { return "a", nil }),
+		delayedResultTask(10*time.Millisecond, func() (string, error) { return "", errExp }),
+		delayedResultTask(time.Second, func() (string, error) { return "slow", nil }),
+		delayedResultTask(20*time.Millisecond, func() (string, error) { return "d", nil }),
+	)
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("ScatterGather should return at the deadline")
+	}
+
+	if len(res) != 4 || len(errs) != 4 {
+		t.Fatalf("unexpected lengths: %d, %d", len(res), len(errs))
+	}
+	if res[0] != "a" || errs[0] != nil || res[3] != "d" || errs[3] != nil {
+		t.Fatalf("unexpected results of succeeded tasks: %v, %v", res, errs)
+	}
+	if errs[1] != errExp || res[1] != "" {
+		t.Fatalf("unexpected result of failed task: %q, %v", res[1], errs[1])
+	}
+	if errs[2] != context.DeadlineExceeded || res[2] != "" {
+		t.Fatalf("unexpected result of timed out task: %q, %v", res[2], errs[2])
+	}
+}
@@ -0,0 +1,286 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// MapPromises applies f to the result of each of ps as soon as it resolves, on the Group, with at most limit transformations running at once (limit <= 0 means no limit).
+// It pipelines a fan-out and an expensive transformation of its results, with concurrency control independent of the Group's limit.
+//
+// The returned Promises are aligned with ps. If an input Promise failed, f is not called for it and the corresponding output Promise holds the same error.
+func MapPromises[T, U any](pg *Group, ps []*Promise[T], limit int, f func(context.Context, T) (U, error)) []*Promise[U] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+
+	qs := make([]*Promise[U], len(ps))
+	for i, p := range ps {
+		p, q := p, newPromise[U](pg)
+		qs[i] = q
+
+		task, settle := promiseTask(q, func(ctx context.Context) (U, error) {
+			var zero U
+			v, err := p.await(ctx)
+			if err != nil {
+				// the error is not caused by this task; it should have been reported by the upstream task or the cancellation.
+				return zero, propagate(err)
+			}
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					return zero, propagate(ctx.Err())
+				}
+			}
+			return f(ctx, v)
+		})
+		pg.launch(nil, task, settle)
+	}
+	return qs
+}
+
+// GoOrElse launches a task which runs primary, and if it fails, runs fallback instead of failing the Group.
+// Only if fallback also fails, its error is reported to the Group.
+//
+// Cancellation (the task's context is done, or primary returned a context error) doesn't trigger fallback, and the error of primary is reported as it is.
+func GoOrElse[T any](pg *Group, primary, fallback func(context.Context) (T, error)) *Promise[T] {
+	if primary == nil || fallback == nil {
+		panic(nilTaskMsg)
+	}
+	return Go(pg, func(ctx context.Context) (T, error) {
+		v, err := primary(ctx)
+		if err == nil || ctx.Err() != nil || isContextErr(err) {
+			return v, err
+		}
+		return fallback(ctx)
+	})
+}
+
+// FlatMap launches f as a task on the Group which p belongs to, once p has succeeded, passing its result to f.
+// If p failed, f is not called and the returned Promise short-circuits with the same error as p, which is not reported to the Group again.
+//
+// It is the monadic bind of Promises, the backbone of composable chains; unlike ThenGroup, f doesn't get the Group.
+// It panics if f is nil, or p doesn't belong to any Group (i.e. it's created by NewPromise); use Then for such Promises.
+func FlatMap[T, U any](p *Promise[T], f func(context.Context, T) (U, error)) *Promise[U] {
+	if p.pg == nil {
+		panic(noGroupMsg)
+	}
+	return Then(p.pg, p, f)
+}
+
+// Then is like FlatMap, but launches f on the given Group, which is required for Promises not belonging to any Group (i.e. created by NewPromise).
+//
+// If p belongs to pg and failed, the returned Promise short-circuits with the same error, like FlatMap.
+// Otherwise, the failure of p (or the cancellation of pg while waiting for p) is reported to pg as the error of the task.
+// It panics if f is nil.
+func Then[T, U any](pg *Group, p *Promise[T], f func(context.Context, T) (U, error)) *Promise[U] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	q := newPromise[U](pg)
+
+	task, settle := promiseTask(q, func(ctx context.Context) (U, error) {
+		var zero U
+		if p.pg == pg {
+			<-p.done
+			if p.err != nil {
+				// the error has already been reported to the Group by the task of p.
+				return zero, propagate(p.err)
+			}
+			return f(ctx, p.res)
+		}
+
+		v, err := p.await(ctx)
+		if err != nil {
+			return zero, err
+		}
+		return f(ctx, v)
+	})
+	pg.launch(nil, task, settle)
+
+	return q
+}
+
+// Then is the method form of FlatMap, for chaining transformations which keep the type of the result.
+// It panics if f is nil, or p doesn't belong to any Group (i.e. it's created by NewPromise); use the function Then for such Promises.
+func (p *Promise[T]) Then(f func(context.Context, T) (T, error)) *Promise[T] {
+	return FlatMap(p, f)
+}
+
+// Pipeline returns a function which launches a task on the Group running steps sequentially, each fed with the result of the previous one (the first with the given initial value), and resolves with the result of the last step.
+// It expresses ordered transformations within the Group model: the chain shares the Group's cancellation and error handling.
+//
+// The chain short-circuits on the first error of steps, or when the Group is canceled between steps; the error is reported to the Group.
+// It panics if any of steps is nil.
+func Pipeline[T any](pg *Group, steps ...func(context.Context, T) (T, error)) func(T) *Promise[T] {
+	for _, step := range steps {
+		if step == nil {
+			panic(nilTaskMsg)
+		}
+	}
+
+	return func(v T) *Promise[T] {
+		return Go(pg, func(ctx context.Context) (T, error) {
+			var zero T
+			for _, step := range steps {
+				if err := ctx.Err(); err != nil {
+					return zero, err
+				}
+				var err error
+				if v, err = step(ctx, v); err != nil {
+					return zero, err
+				}
+			}
+			return v, nil
+		})
+	}
+}
+
+// Catch returns a Promise which recovers from the failure of p with handle, if the error of p matches E (see errors.As).
+// If p succeeded, the returned Promise holds the same result. If the error of p doesn't match E, it propagates unchanged.
+// handle runs as a task on the Group which p belongs to; an error returned from it is reported to the Group.
+//
+// Note that the error of p has already been reported to the Group, so the Group is canceled by it unless it is in collect-all mode (see WithCollectAll).
+// It panics if handle is nil, or p doesn't belong to any Group (i.e. it's created by NewPromise).
+func Catch[T any, E error](p *Promise[T], handle func(E) (T, error)) *Promise[T] {
+	if handle == nil {
+		panic(nilTaskMsg)
+	}
+	if p.pg == nil {
+		panic(noGroupMsg)
+	}
+	pg := p.pg
+	q := newPromise[T](pg)
+
+	task, settle := promiseTask(q, func(context.Context) (T, error) {
+		<-p.done
+		if p.err == nil {
+			return p.res, nil
+		}
+		var target E
+		if !errors.As(p.err, &target) {
+			var zero T
+			return zero, propagate(p.err)
+		}
+		return handle(target)
+	})
+	pg.launch(nil, task, settle)
+
+	return q
+}
+
+// ErrNoMatch is the error of the Promise returned from FirstMatch when no task has succeeded with a result satisfying the predicate.
+var ErrNoMatch = errors.New("pgroup: no result matched")
+
+// FirstMatch launches a task which runs tasks concurrently, and resolves with the first successful result satisfying pred. The rest of tasks are canceled as soon as a result matches, with the cause ErrFirstSuccess (see context.Cause).
+// Results not satisfying pred are ignored, and failures of tasks don't cancel the others.
+//
+// If no result matches, the returned Promise fails with an error wrapping ErrNoMatch and the errors of failed tasks (see errors.Join), which is reported to the Group.
+// pred may be called concurrently.
+// It panics if pred or any of tasks is nil.
+func FirstMatch[T any](pg *Group, pred func(T) bool, tasks ...func(context.Context) (T, error)) *Promise[T] {
+	if pred == nil {
+		panic(nilTaskMsg)
+	}
+	for _, f := range tasks {
+		if f == nil {
+			panic(nilTaskMsg)
+		}
+	}
+
+	return Go(pg, func(ctx context.Context) (T, error) {
+		sub := WithContext(ctx, WithCollectAll())
+
+		var (
+			once    sync.Once
+			matched bool
+			winner  T
+		)
+		for _, f := range tasks {
+			f := f
+			GoAndForget(sub, func(ctx context.Context) error {
+				v, err := f(ctx)
+				if err != nil {
+					return err
+				}
+				if pred(v) {
+					once.Do(func() {
+						matched, winner = true, v
+						sub.Cancel(ErrFirstSuccess)
+					})
+				}
+				return nil
+			})
+		}
+
+		err := sub.Wait()
+		if matched {
+			return winner, nil
+		}
+		var zero T
+		return zero, errors.Join(ErrNoMatch, err)
+	})
+}
+
+// Either is a tagged union of values of two types, the result of RaceEither. IsLeft reports which of Left and Right holds the value.
+type Either[A, B any] struct {
+	Left   A
+	Right  B
+	IsLeft bool
+}
+
+// RaceEither launches a task which runs a and b concurrently, and resolves with the result of whichever succeeds first, canceling the other with the cause ErrFirstSuccess (see context.Cause).
+// It's useful for redundant strategies returning results of different shapes.
+//
+// If a fails, the result of b is awaited, and vice versa. If both fail, the returned Promise fails with both errors joined (see errors.Join), which is reported to the Group.
+// It panics if a or b is nil.
+func RaceEither[A, B any](pg *Group, a func(context.Context) (A, error), b func(context.Context) (B, error)) *Promise[Either[A, B]] {
+	if a == nil || b == nil {
+		panic(nilTaskMsg)
+	}
+
+	return Go(pg, func(ctx context.Context) (Either[A, B], error) {
+		sub := WithContext(ctx, WithCollectAll())
+
+		var (
+			once   sync.Once
+			won    bool
+			winner Either[A, B]
+		)
+		settle := func(res Either[A, B]) {
+			once.Do(func() {
+				won, winner = true, res
+				sub.Cancel(ErrFirstSuccess)
+			})
+		}
+		GoAndForget(sub, func(ctx context.Context) error {
+			v, err := a(ctx)
+			if err == nil {
+				settle(Either[A, B]{Left: v, IsLeft: true})
+			}
+			return err
+		})
+		GoAndForget(sub, func(ctx context.Context) error {
+			v, err := b(ctx)
+			if err == nil {
+				settle(Either[A, B]{Right: v})
+			}
+			return err
+		})
+
+		err := sub.Wait()
+		if won {
+			return winner, nil
+		}
+		return Either[A, B]{}, err
+	})
+}
@@ -0,0 +1,404 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapPromises(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New(WithCollectAll())
+
+	ps := make([]*Promise[int], 0, 5)
+	for i := 0; i < 5; i++ {
+		i := i
+		ps = append(ps, Go(pg, delayedResultTask(time.Duration(i)*10*time.Millisecond, func() (int, error) {
+			if i == 3 {
+				return 0, errExp
+			}
+			return i, nil
+		})))
+	}
+
+	var active, maxActive int32
+	qs := MapPromises(pg, ps, 2, func(ctx context.Context, n int) (int, error) {
+		a := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if a <= m || atomic.CompareAndSwapInt32(&maxActive, m, a) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return n * 10, nil
+	})
+
+	err := pg.Wait()
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 1 {
+		t.Fatalf("the upstream error should be reported only once: %v", err)
+	}
+	if maxActive > 2 {
+		t.Fatalf("transformations should run at most 2 at once: %d", maxActive)
+	}
+
+	for i, q := range qs {
+		if i == 3 {
+			if q.err != errExp {
+				t.Fatalf("unexpected error of promise %d: %v", i, q.err)
+			}
+			continue
+		}
+		if q.Get() != i*10 {
+			t.Fatalf("unexpected result of promise %d: %v", i, q.Get())
+		}
+	}
+}
+
+func TestGoOrElse(t *testing.T) {
+	errPrimary := errors.New("primary failed")
+	errFallback := errors.New("fallback failed")
+
+	ok := func(v string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { return v, nil }
+	}
+	fail := func(err error) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { return "", err }
+	}
+
+	pg := New()
+	p1 := GoOrElse(pg, ok("primary"), ok("fallback"))
+	p2 := GoOrElse(pg, fail(errPrimary), ok("fallback"))
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.Get() != "primary" || p2.Get() != "fallback" {
+		t.Fatalf("unexpected results: %v, %v", p1.Get(), p2.Get())
+	}
+
+	pg = New()
+	GoOrElse(pg, fail(errPrimary), fail(errFallback))
+	if err := pg.Wait(); err != errFallback {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// cancellation doesn't trigger the fallback.
+	pg = New()
+	called := false
+	GoOrElse(pg, fail(context.Canceled), func(context.Context) (string, error) { called = true; return "", nil })
+	if err := pg.Wait(); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Fatal("fallback should not be called on cancellation")
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	errExp := errors.New("error!")
+	var calls atomic.Int32
+	double := func(_ context.Context, v int) (string, error) {
+		calls.Add(1)
+		return fmt.Sprint(v * 2), nil
+	}
+
+	ok := FlatMap(Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 21, nil })), double)
+	ng := FlatMap(Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 0, errExp })), double)
+	chained := FlatMap(ng, func(_ context.Context, s string) (string, error) {
+		calls.Add(1)
+		return s + "!", nil
+	})
+
+	err := pg.Wait()
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 1 {
+		t.Fatalf("short-circuited errors should not be reported again: %v", err)
+	}
+	if ok.Get() != "42" {
+		t.Fatalf("unexpected result: %v", ok.Get())
+	}
+	if ng.err != errExp || chained.err != errExp {
+		t.Fatalf("errors should short-circuit: %v, %v", ng.err, chained.err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("f should not be called for failed Promises: %d", n)
+	}
+	assertPanics(t, nilTaskMsg, func() { FlatMap(ok, (func(context.Context, string) (int, error))(nil)) })
+}
+
+type notFoundError struct{ key string }
+
+func (e *notFoundError) Error() string { return "not found: " + e.key }
+
+func TestCatch(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	errOther := errors.New("other")
+	ok := Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 1, nil }))
+	nf := Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 0, &notFoundError{"x"} }))
+	other := Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 0, errOther }))
+
+	var handled []string
+	handle := func(e *notFoundError) (int, error) {
+		handled = append(handled, e.key)
+		return -1, nil
+	}
+	okC := Catch(ok, handle)
+	nfC := Catch(nf, handle)
+	otherC := Catch(other, handle)
+
+	err := pg.Wait()
+	if !errors.Is(err, errOther) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if okC.Get() != 1 || okC.err != nil {
+		t.Fatalf("unexpected result of succeeded Promise: %v, %v", okC.Get(), okC.err)
+	}
+	if nfC.Get() != -1 || nfC.err != nil {
+		t.Fatalf("unexpected result of recovered Promise: %v, %v", nfC.Get(), nfC.err)
+	}
+	if !errors.Is(otherC.err, errOther) {
+		t.Fatalf("unmatched error should propagate: %v", otherC.err)
+	}
+	if len(handled) != 1 || handled[0] != "x" {
+		t.Fatalf("unexpected handled errors: %v", handled)
+	}
+}
+
+func TestFirstMatch(t *testing.T) {
+	pg := New()
+
+	var canceled atomic.Bool
+	p := FirstMatch(pg, func(v int) bool { return v%2 == 0 },
+		delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }),
+		delayedResultTask(10*time.Millisecond, func() (int, error) { return 0, errors.New("ignored") }),
+		delayedResultTask(20*time.Millisecond, func() (int, error) { return 4, nil }),
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			canceled.Store(true)
+			return 0, ctx.Err()
+		},
+	)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() != 4 {
+		t.Fatalf("unexpected result: %v", p.Get())
+	}
+	if !canceled.Load() {
+		t.Fatal("the rest of tasks should be canceled")
+	}
+}
+
+func TestFirstMatch_noMatch(t *testing.T) {
+	pg := New()
+
+	errExp := errors.New("error!")
+	FirstMatch(pg, func(v int) bool { return v > 10 },
+		delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }),
+		delayedResultTask(10*time.Millisecond, func() (int, error) { return 0, errExp }),
+	)
+
+	err := pg.Wait()
+	if !errors.Is(err, ErrNoMatch) || !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRaceEither(t *testing.T) {
+	pg := New()
+
+	var loserCanceled atomic.Bool
+	p := RaceEither(pg,
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			loserCanceled.Store(true)
+			return 0, ctx.Err()
+		},
+		delayedResultTask(10*time.Millisecond, func() (string, error) { return "b", nil }),
+	)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res := p.Get(); res.IsLeft || res.Right != "b" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if !loserCanceled.Load() {
+		t.Fatal("the loser should be canceled")
+	}
+}
+
+func TestRaceEither_failure(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	// a failure of one side waits for the other
+	pg := New()
+	p := RaceEither(pg,
+		delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }),
+		delayedResultTask(time.Millisecond, func() (string, error) { return "", errB }),
+	)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res := p.Get(); !res.IsLeft || res.Left != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	pg = New()
+	RaceEither(pg,
+		delayedResultTask(time.Millisecond, func() (int, error) { return 0, errA }),
+		delayedResultTask(time.Millisecond, func() (string, error) { return "", errB }),
+	)
+	if err := pg.Wait(); !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCombinators_cancelCause(t *testing.T) {
+	// causeTask blocks until canceled, and records the cause of the cancellation.
+	causeTask := func(cause *atomic.Value) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			cause.Store(context.Cause(ctx))
+			return 0, ctx.Err()
+		}
+	}
+	win := delayedResultTask(5*time.Millisecond, func() (int, error) { return 1, nil })
+
+	t.Run("FirstMatch", func(t *testing.T) {
+		var cause atomic.Value
+		pg := New()
+		FirstMatch(pg, func(int) bool { return true }, win, causeTask(&cause))
+		if err := pg.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := cause.Load(); c != ErrFirstSuccess {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("RaceEither", func(t *testing.T) {
+		var cause atomic.Value
+		pg := New()
+		RaceEither(pg, win, causeTask(&cause))
+		if err := pg.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := cause.Load(); c != ErrFirstSuccess {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("WithFirstSuccessWins", func(t *testing.T) {
+		var cause atomic.Value
+		pg := New(WithFirstSuccessWins())
+		Go(pg, win)
+		Go(pg, causeTask(&cause))
+		if err := pg.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := cause.Load(); c != ErrFirstSuccess {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("ScatterGather", func(t *testing.T) {
+		var cause atomic.Value
+		ScatterGather(context.Background(), 5*time.Millisecond, win, causeTask(&cause))
+
+		deadline := time.Now().Add(time.Second)
+		for cause.Load() == nil && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if c := cause.Load(); c != context.DeadlineExceeded {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("sibling failure", func(t *testing.T) {
+		var cause atomic.Value
+		errExp := errors.New("error!")
+		pg := New()
+		RaceEither(pg, causeTask(&cause), causeTask(&atomic.Value{}))
+		Go(pg, delayedResultTask(5*time.Millisecond, func() (int, error) { return 0, errExp }))
+		if err := pg.Wait(); err != errExp {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := cause.Load(); c != errExp {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+}
+
+func TestPromise_Then(t *testing.T) {
+	pg := New()
+
+	inc := func(_ context.Context, v int) (int, error) { return v + 1, nil }
+	p := Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return 1, nil })).Then(inc).Then(inc)
+
+	// external Promises need the explicit form
+	ext, resolve := NewPromise[int]()
+	q := Then(pg, ext, func(_ context.Context, v int) (string, error) { return fmt.Sprint(v), nil })
+	time.AfterFunc(time.Millisecond, func() { resolve(10, nil) })
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() != 3 || q.Get() != "10" {
+		t.Fatalf("unexpected results: %v, %v", p.Get(), q.Get())
+	}
+	assertPanics(t, noGroupMsg, func() { ext.Then(inc) })
+
+	// failures of external Promises are reported to the Group
+	errExp := errors.New("error!")
+	pg = New()
+	ext, resolve = NewPromise[int]()
+	Then(pg, ext, inc)
+	resolve(0, errExp)
+	if err := pg.Wait(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	var calls atomic.Int32
+	double := func(_ context.Context, v int) (int, error) { calls.Add(1); return v * 2, nil }
+	inc := func(_ context.Context, v int) (int, error) { calls.Add(1); return v + 1, nil }
+	run := Pipeline(pg, double, inc, double)
+
+	p1, p2 := run(1), run(10)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.Get() != 6 || p2.Get() != 42 {
+		t.Fatalf("unexpected results: %v, %v", p1.Get(), p2.Get())
+	}
+
+	// short-circuits on error
+	errExp := errors.New("error!")
+	calls.Store(0)
+	pg = New()
+	p := Pipeline(pg, double, func(context.Context, int) (int, error) { return 0, errExp }, inc)(1)
+	if err := pg.Wait(); err != errExp || p.err != errExp {
+		t.Fatalf("unexpected error: %v, %v", err, p.err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("steps after the failure should not run: %d", calls.Load())
+	}
+}
@@ -0,0 +1,116 @@
+package pgroup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Config is a reusable configuration of Groups. It is useful for services with a standard "profile" of Groups, to avoid repeating the same options at every call site.
+//
+// The zero value is the default configuration, equivalent to WithContext without options.
+type Config struct {
+	// Name of Groups (see WithContextNamed). Empty means unnamed.
+	Name string
+
+	// Limit of the number of active tasks (see SetLimit). 0 means no limit.
+	Limit int
+	// FairLimiter enables the fair limiter (see WithFairLimiter).
+	FairLimiter bool
+	// RateLimit and RateLimitPer limit the rate of launching tasks to RateLimit tasks per RateLimitPer (see WithRateLimit). 0 means no limit.
+	// RateLimitPer defaults to one second if it's not positive.
+	RateLimit    int
+	RateLimitPer time.Duration
+
+	// CollectAll enables collect-all mode (see WithCollectAll).
+	CollectAll bool
+	// MaxCollectedErrors limits the number of errors retained in collect-all mode (see WithMaxCollectedErrors).
+	MaxCollectedErrors int
+
+	// FirstSuccessWins enables first-success-wins mode (see WithFirstSuccessWins).
+	FirstSuccessWins bool
+
+	// FailFast specifies the behavior if the parent context has already been canceled (see WithFailFastOnCanceledParent). 0 means the default behavior.
+	FailFast FailFastMode
+
+	// Recover makes Groups recover from panics in tasks (see WithRecover). PanicHandler takes precedence if both are set.
+	Recover bool
+	// PanicHandler handles panics in tasks (see WithPanicHandler).
+	PanicHandler func(r any, stack []byte) error
+	// CancelErrorMapper rewrites errors of canceled tasks (see WithCancelErrorMapper).
+	CancelErrorMapper func(error) error
+
+	// Workers is the number of workers running tasks (see WithWorkers). 0 means launching a new goroutine for each task.
+	Workers int
+
+	// Timeout is the budget of wall-clock time on the whole Group (see WithTimeout). 0 means no timeout.
+	Timeout time.Duration
+	// WaitTimeout is the timeout of Wait (see WithWaitTimeout). 0 means no timeout.
+	WaitTimeout time.Duration
+
+	// Logger for lifecycle of tasks (see WithLogger). nil means no logging.
+	Logger *slog.Logger
+
+	// Options are additional options applied after the ones derived from the fields above.
+	Options []Option
+}
+
+// New returns a new Group with the parent context, configured by c.
+func (c Config) New(ctx context.Context) *Group {
+	var opts []Option
+	if c.Limit > 0 {
+		opts = append(opts, WithLimit(c.Limit))
+	}
+	if c.FairLimiter {
+		opts = append(opts, WithFairLimiter())
+	}
+	if c.RateLimit > 0 {
+		per := c.RateLimitPer
+		if per <= 0 {
+			per = time.Second
+		}
+		opts = append(opts, WithRateLimit(c.RateLimit, per))
+	}
+	if c.CollectAll {
+		opts = append(opts, WithCollectAll())
+	}
+	if c.MaxCollectedErrors > 0 {
+		opts = append(opts, WithMaxCollectedErrors(c.MaxCollectedErrors))
+	}
+	if c.FirstSuccessWins {
+		opts = append(opts, WithFirstSuccessWins())
+	}
+	if c.FailFast != 0 {
+		opts = append(opts, WithFailFastOnCanceledParent(c.FailFast))
+	}
+	if c.Recover {
+		opts = append(opts, WithRecover())
+	}
+	if c.PanicHandler != nil {
+		opts = append(opts, WithPanicHandler(c.PanicHandler))
+	}
+	if c.CancelErrorMapper != nil {
+		opts = append(opts, WithCancelErrorMapper(c.CancelErrorMapper))
+	}
+	if c.Workers > 0 {
+		opts = append(opts, WithWorkers(c.Workers))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.WaitTimeout > 0 {
+		opts = append(opts, WithWaitTimeout(c.WaitTimeout))
+	}
+	opts = append(opts, c.Options...)
+
+	var pg *Group
+	if c.Name != "" {
+		pg = WithContextNamed(ctx, c.Name, opts...)
+	} else {
+		pg = WithContext(ctx, opts...)
+	}
+	if c.Logger != nil {
+		pg.logger = c.Logger
+	}
+	return pg
+}
@@ -0,0 +1,44 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfig_New(t *testing.T) {
+	cfg := Config{
+		Name:       "batch",
+		Limit:      2,
+		CollectAll: true,
+	}
+
+	for i := 0; i < 2; i++ {
+		pg := cfg.New(context.Background())
+		if pg.Name() != "batch" {
+			t.Fatalf("unexpected name: %q", pg.Name())
+		}
+		if pg.limiter == nil || pg.limiter.size() != 2 {
+			t.Fatal("limit should be set")
+		}
+
+		err1, err2 := errors.New("error 1"), errors.New("error 2")
+		GoAndForget(pg, func(context.Context) error { return err1 })
+		GoAndForget(pg, func(context.Context) error { return err2 })
+
+		err := pg.Wait()
+		if !errors.Is(err, err1) || !errors.Is(err, err2) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestConfig_New_rateLimitPerDefault(t *testing.T) {
+	pg := Config{RateLimit: 10}.New(context.Background())
+	defer pg.Wait()
+
+	if pg.rate == nil || pg.rate.interval != time.Second/10 {
+		t.Fatal("RateLimitPer should default to one second")
+	}
+}
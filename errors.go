@@ -0,0 +1,216 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errorList is a concurrency-safe list of errors collected from tasks in collect-all mode.
+type errorList struct {
+	mu sync.Mutex
+
+	errs []error
+	// max number of errors to be retained. 0 means no limit.
+	max int
+	// number of errors dropped due to the limit.
+	dropped int
+	// whether the list no longer accepts errors. See seal.
+	sealed bool
+}
+
+// add appends err to the list, and returns the number of errors added so far (including dropped ones).
+func (l *errorList) add(err error) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sealed {
+		return len(l.errs) + l.dropped
+	}
+	if l.max > 0 && len(l.errs) >= l.max {
+		l.dropped++
+	} else {
+		l.errs = append(l.errs, err)
+	}
+	return len(l.errs) + l.dropped
+}
+
+// list returns a copy of the collected errors.
+func (l *errorList) list() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]error(nil), l.errs...)
+}
+
+// reset clears the list. The limit is kept.
+func (l *errorList) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs = nil
+	l.dropped = 0
+	l.sealed = false
+}
+
+// seal makes the list ignore errors added afterwards, so that the result of err stays the same.
+func (l *errorList) seal() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sealed = true
+}
+
+// err returns all collected errors joined into an error, or nil if no error was collected.
+// Errors for which skip reports true are excluded. skip == nil means no error is excluded.
+func (l *errorList) err(skip func(error) bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	errs := make([]error, 0, len(l.errs))
+	for _, err := range l.errs {
+		if skip == nil || !skip(err) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if l.dropped == 0 {
+		return errors.Join(errs...)
+	}
+	return &truncatedErrors{errs: errs, dropped: l.dropped}
+}
+
+// truncatedErrors is a joined error of collected errors, some of which were dropped due to the limit set by WithMaxCollectedErrors.
+type truncatedErrors struct {
+	errs    []error
+	dropped int
+}
+
+func (e *truncatedErrors) Error() string {
+	var b strings.Builder
+	for _, err := range e.errs {
+		b.WriteString(err.Error())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "... and %d more", e.dropped)
+	return b.String()
+}
+
+func (e *truncatedErrors) Unwrap() []error {
+	return e.errs
+}
+
+// groupError is an error returned from Wait of a named Group, prefixed with the name of the Group.
+type groupError struct {
+	name string
+	err  error
+}
+
+// wrapGroupError prefixes err with the name of a Group.
+// If err is already prefixed by an inner Group, whose name includes the name of the Group, it leaves err as it is, so that the message looks like "outer/inner: err" rather than "outer: outer/inner: err".
+func wrapGroupError(name string, err error) error {
+	if ge, ok := err.(*groupError); ok && strings.HasPrefix(ge.name, name+"/") {
+		return err
+	}
+	return &groupError{name: name, err: err}
+}
+
+// unwrapGroupError strips prefixes of names of Groups from err.
+func unwrapGroupError(err error) error {
+	for {
+		ge, ok := err.(*groupError)
+		if !ok {
+			return err
+		}
+		err = ge.err
+	}
+}
+
+func (e *groupError) Error() string {
+	return e.name + ": " + e.err.Error()
+}
+
+func (e *groupError) Unwrap() error {
+	return e.err
+}
+
+// propagatedError wraps an error which is not caused by the task itself but propagated from elsewhere (e.g. a failure of the upstream task).
+// It settles the result of the task, but is not reported to the Group again.
+type propagatedError struct {
+	err error
+}
+
+func propagate(err error) error {
+	return &propagatedError{err: err}
+}
+
+func (e *propagatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *propagatedError) Unwrap() error {
+	return e.err
+}
+
+// isolatedError wraps an error which fails only the task itself: a panic isolated by WithIsolatedPanics, or an error of a task launched by GoSideEffect.
+// Like propagatedError, it settles the result of the task, but is not reported to the Group.
+type isolatedError struct {
+	err error
+}
+
+func (e *isolatedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *isolatedError) Unwrap() error {
+	return e.err
+}
+
+// DeadlineError is the error returned from Wait when the Group failed due to the deadline of its context (i.e. the deadline of the parent context).
+// It wraps the original error (so errors.Is(err, context.DeadlineExceeded) holds), and reports the deadline and how much the Group overran it, for SLA analysis.
+type DeadlineError struct {
+	err      error
+	deadline time.Time
+	finished time.Time
+}
+
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("%v (deadline overrun by %v)", e.err, e.Overrun())
+}
+
+func (e *DeadlineError) Unwrap() error {
+	return e.err
+}
+
+// Deadline returns the deadline of the Group's context.
+func (e *DeadlineError) Deadline() time.Time {
+	return e.deadline
+}
+
+// Overrun returns how long after the deadline the Group finished, i.e. all the tasks completed.
+func (e *DeadlineError) Overrun() time.Duration {
+	return e.finished.Sub(e.deadline)
+}
+
+// wrapDeadlineError wraps err with DeadlineError if it's caused by the deadline of the Group's context.
+func (pg *Group) wrapDeadlineError(err error) error {
+	deadline, ok := pg.ctx.Deadline()
+	if !ok || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	finished := pg.finishedAt
+	if finished.IsZero() {
+		finished = time.Now()
+	}
+	if finished.Before(deadline) {
+		// the deadline of the Group has not passed; the error came from elsewhere (e.g. a timeout of a task).
+		return err
+	}
+	return &DeadlineError{err: err, deadline: deadline, finished: finished}
+}
@@ -0,0 +1,223 @@
+package pgroup
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// WithSpawner makes the Group use spawn to run tasks, instead of launching a new goroutine for each task.
+// spawn is called with a function that runs a task, and it must eventually call the function exactly once (it may be called synchronously).
+//
+// It is an extension point to customize how tasks are executed, e.g. running them on a pool of goroutines.
+func WithSpawner(spawn func(run func())) Option {
+	return func(pg *Group) {
+		pg.spawn = spawn
+	}
+}
+
+// WithWorkers makes the Group run tasks on a fixed pool of n goroutines ("workers"), instead of launching a new goroutine for each task.
+// Tasks are queued and run in the submission order as workers become available. Launching tasks never blocks due to the pool.
+//
+// Since tasks occupy workers until they complete, tasks must not wait on other tasks queued later; otherwise they may deadlock.
+// Workers exit at the end of Wait(). Tasks launched after that are run on new goroutines, until the Group is reset (see Group.Reset).
+func WithWorkers(n int) Option {
+	return WithPrewarmedWorkers(n, 0)
+}
+
+// WithPrewarmedWorkers is like WithWorkers, but each worker grows its stack by about stackWarmup bytes before running tasks.
+// It is a performance optimization for deeply-recursive tasks, which avoids repeated stack growth on each new goroutine (Go doesn't allow setting the stack size of goroutines directly).
+//
+// Note that it's best-effort: the runtime may shrink stacks of idle workers during garbage collection.
+func WithPrewarmedWorkers(n int, stackWarmup int) Option {
+	return func(pg *Group) {
+		wp := newWorkerPool(n, stackWarmup)
+		pg.workers = wp
+		pg.spawn = wp.submit
+	}
+}
+
+// WithSyncExecutor makes the Group run each task synchronously on the goroutine launching it, without spawning any goroutines.
+// Tasks run one at a time in the submission order, so side-effects of tasks happen in a predictable sequence, which is useful for deterministic (e.g. golden-file-style) tests.
+//
+// In this mode, Go (and other functions launching tasks) return after the task has completed, so the returned Promise has already been resolved.
+// Since tasks never run concurrently, tasks must not wait on other tasks launched later; otherwise they deadlock.
+func WithSyncExecutor() Option {
+	return WithSpawner(func(run func()) { run() })
+}
+
+// WithDeterministicScheduler makes the Group run tasks one at a time in a pseudo-random order determined by seed, to reproduce a specific interleaving of tasks while investigating order-dependent flakiness.
+// Running the same tasks with the seed recorded from a failing run replays the same order.
+//
+// Tasks are queued on launch, and run on the goroutine calling Wait (or its variants) until no task is left, including tasks launched by other tasks.
+// It is intended for tests only: tasks must not wait on other tasks, nor block on anything which requires concurrent progress; otherwise they deadlock.
+// For the same reason, it must not be combined with limits of the number of active tasks (e.g. SetLimit), which block launches until queued tasks complete.
+func WithDeterministicScheduler(seed int64) Option {
+	return func(pg *Group) {
+		s := &deterministicScheduler{rng: rand.New(rand.NewSource(seed))}
+		pg.sched = s
+		pg.spawn = s.submit
+	}
+}
+
+// spawnTask runs the task by the configured spawner, or on a new goroutine by default.
+func (pg *Group) spawnTask(run func()) {
+	if pg.spawn != nil {
+		pg.spawn(run)
+		return
+	}
+	go run()
+}
+
+// QueueDepth returns the number of tasks queued but not started yet, in the worker-pool mode (see WithWorkers).
+// It lets producers monitor the backlog, e.g. for autoscaling the number of workers or shedding load.
+// It always returns 0 unless the Group runs tasks on workers.
+func (pg *Group) QueueDepth() int {
+	if pg.workers == nil {
+		return 0
+	}
+	return pg.workers.depth()
+}
+
+// workerPool is a fixed number of goroutines which run queued tasks.
+type workerPool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []func()
+	closed bool
+
+	n           int
+	stackWarmup int
+}
+
+func newWorkerPool(n int, stackWarmup int) *workerPool {
+	wp := &workerPool{n: n, stackWarmup: stackWarmup}
+	wp.cond = sync.NewCond(&wp.mu)
+	wp.startWorkers()
+	return wp
+}
+
+func (wp *workerPool) startWorkers() {
+	for i := 0; i < wp.n; i++ {
+		go wp.work(wp.stackWarmup)
+	}
+}
+
+// reopen restarts workers of the closed pool, for the next round of the Group (see Group.Reset).
+func (wp *workerPool) reopen() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if !wp.closed {
+		return
+	}
+	wp.closed = false
+	wp.startWorkers()
+}
+
+func (wp *workerPool) submit(run func()) {
+	wp.mu.Lock()
+	if wp.closed {
+		wp.mu.Unlock()
+		go run()
+		return
+	}
+	wp.queue = append(wp.queue, run)
+	wp.mu.Unlock()
+
+	wp.cond.Signal()
+}
+
+// depth returns the number of queued tasks.
+func (wp *workerPool) depth() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return len(wp.queue)
+}
+
+// close makes workers exit after running all queued tasks.
+func (wp *workerPool) close() {
+	wp.mu.Lock()
+	wp.closed = true
+	wp.mu.Unlock()
+
+	wp.cond.Broadcast()
+}
+
+func (wp *workerPool) work(stackWarmup int) {
+	if stackWarmup > 0 {
+		growStack(stackWarmup)
+	}
+	for {
+		wp.mu.Lock()
+		for len(wp.queue) == 0 && !wp.closed {
+			wp.cond.Wait()
+		}
+		if len(wp.queue) == 0 {
+			wp.mu.Unlock()
+			return
+		}
+		run := wp.queue[0]
+		wp.queue[0] = nil
+		wp.queue = wp.queue[1:]
+		wp.mu.Unlock()
+
+		run()
+	}
+}
+
+// stackFrameSize is the approximate size of a frame of growStack.
+const stackFrameSize = 1024
+
+// growStack recurses until it consumes about n bytes of the stack, so that the stack of the calling goroutine grows to that size.
+//
+//go:noinline
+func growStack(n int) byte {
+	var buf [stackFrameSize]byte
+	buf[n%stackFrameSize] = byte(n)
+	if n > stackFrameSize {
+		return growStack(n-stackFrameSize) + buf[0]
+	}
+	return buf[0]
+}
+
+// deterministicScheduler queues tasks, and runs them one at a time in a seeded pseudo-random order on drain.
+type deterministicScheduler struct {
+	// serializes drains, so that tasks never run concurrently even if Wait is called concurrently.
+	drainMu sync.Mutex
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	pending []func()
+}
+
+func (s *deterministicScheduler) submit(run func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, run)
+}
+
+// next picks the next task to run at random, or returns nil if no task is pending.
+func (s *deterministicScheduler) next() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.pending)
+	if n == 0 {
+		return nil
+	}
+	i := s.rng.Intn(n)
+	run := s.pending[i]
+	s.pending[i] = s.pending[n-1]
+	s.pending = s.pending[:n-1]
+	return run
+}
+
+// drain runs pending tasks until no task is left.
+func (s *deterministicScheduler) drain() {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	for run := s.next(); run != nil; run = s.next() {
+		run()
+	}
+}
@@ -0,0 +1,186 @@
+package pgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithWorkers(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	pg := New(WithPrewarmedWorkers(2, 64*1024))
+
+	var active, maxActive int32
+	ps := make([]*Promise[int], 0, 10)
+	for i := 0; i < 10; i++ {
+		i := i
+		ps = append(ps, Go(pg, func(ctx context.Context) (int, error) {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return i, nil
+		}))
+	}
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxActive > 2 {
+		t.Fatalf("tasks should run on at most 2 workers: %d", maxActive)
+	}
+	for i, p := range ps {
+		if p.Get() != i {
+			t.Fatalf("unexpected result (want: %v, got: %v)", i, p.Get())
+		}
+	}
+
+	// workers exit after Wait.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("workers should exit after Wait (before: %d, after: %d)", before, n)
+	}
+}
+
+func TestWithSpawner(t *testing.T) {
+	var spawned int32
+	pg := New(WithSpawner(func(run func()) {
+		atomic.AddInt32(&spawned, 1)
+		go run()
+	}))
+
+	GoAndForget(pg, func(ctx context.Context) error { return nil })
+	GoAndForget(pg, func(ctx context.Context) error { return nil })
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spawned != 2 {
+		t.Fatalf("unexpected number of spawned tasks: %d", spawned)
+	}
+}
+
+func TestWithSyncExecutor(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	pg := New(WithSyncExecutor())
+
+	var order []int
+	for i := 0; i < 5; i++ {
+		i := i
+		p := Go(pg, func(context.Context) (int, error) {
+			if n := runtime.NumGoroutine(); n > before {
+				t.Errorf("task should run without spawning goroutines (before: %d, now: %d)", before, n)
+			}
+			order = append(order, i)
+			return i * 10, nil
+		})
+
+		// the Promise has already been resolved when Go returns
+		select {
+		case <-p.done:
+		default:
+			t.Fatal("Promise should be resolved")
+		}
+		if p.Get() != i*10 {
+			t.Fatalf("unexpected result: %v", p.Get())
+		}
+	}
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("tasks should run in the submission order: %v", order)
+		}
+	}
+}
+
+func TestWithDeterministicScheduler(t *testing.T) {
+	run := func(seed int64) []int {
+		pg := New(WithDeterministicScheduler(seed))
+
+		var order []int
+		for i := 0; i < 8; i++ {
+			i := i
+			GoAndForget(pg, func(context.Context) error {
+				order = append(order, i)
+				if i == 0 {
+					// tasks launched by tasks are also scheduled
+					GoAndForget(pg, func(context.Context) error {
+						order = append(order, 100)
+						return nil
+					})
+				}
+				return nil
+			})
+		}
+		if len(order) != 0 {
+			t.Fatal("tasks should not run before Wait")
+		}
+		if err := pg.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return order
+	}
+
+	first := run(42)
+	if len(first) != 9 {
+		t.Fatalf("all tasks should run: %v", first)
+	}
+	if again := run(42); fmt.Sprint(first) != fmt.Sprint(again) {
+		t.Fatalf("the same seed should replay the same order: %v, %v", first, again)
+	}
+
+	differs := false
+	for seed := int64(0); seed < 10 && !differs; seed++ {
+		differs = fmt.Sprint(run(seed)) != fmt.Sprint(first)
+	}
+	if !differs {
+		t.Fatal("different seeds should produce different orders")
+	}
+}
+
+func TestQueueDepth(t *testing.T) {
+	if d := New().QueueDepth(); d != 0 {
+		t.Fatalf("queue depth should be 0 without workers: %d", d)
+	}
+
+	pg := New(WithWorkers(1))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	GoAndForget(pg, func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	for i := 0; i < 3; i++ {
+		GoAndForget(pg, func(context.Context) error { return nil })
+	}
+
+	if d := pg.QueueDepth(); d != 3 {
+		t.Fatalf("unexpected queue depth: %d", d)
+	}
+	close(release)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := pg.QueueDepth(); d != 0 {
+		t.Fatalf("queue should be drained: %d", d)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/jiftechnify/pgroup
 
-go 1.19
+go 1.21
@@ -0,0 +1,212 @@
+package pgroup
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// lifecycleHooks holds hooks run on cancellation or completion of a Group.
+type lifecycleHooks struct {
+	mu sync.Mutex
+
+	cancelHooks []func(err error)
+	cancelFired bool
+	cancelOnce  sync.Once
+	// registers the trigger of cancel hooks on the Group's context lazily.
+	watchOnce sync.Once
+	// cancel hooks to be run one at a time, after they have been fired. See runQueue.
+	cancelQueue hookQueue
+	// signaled when a queue of hooks becomes idle. Created lazily, since it needs mu.
+	idle *sync.Cond
+
+	doneHooks []func()
+	doneFired bool
+	doneQueue hookQueue
+}
+
+// hookQueue is a queue of hooks to be run one at a time, even if they are registered (late) from multiple goroutines.
+type hookQueue struct {
+	pending []func()
+	// whether any goroutine is running hooks in the queue.
+	running bool
+}
+
+// runQueue enqueues hooks to q, and runs hooks in q until it becomes empty, unless another goroutine is already running them; then the goroutine runs the enqueued hooks after the current one.
+// Since hooks registered from inside of a running hook are just enqueued, it never deadlocks.
+// It must be called with h.mu held, and it releases h.mu.
+func (h *lifecycleHooks) runQueue(q *hookQueue, hooks ...func()) {
+	q.pending = append(q.pending, hooks...)
+	if q.running {
+		h.mu.Unlock()
+		return
+	}
+	q.running = true
+	for len(q.pending) > 0 {
+		f := q.pending[0]
+		q.pending = q.pending[1:]
+		h.mu.Unlock()
+		f()
+		h.mu.Lock()
+	}
+	q.running = false
+	if h.idle != nil {
+		h.idle.Broadcast()
+	}
+	h.mu.Unlock()
+}
+
+// reset unregisters all hooks, and rearms them for the next round of the Group (see Group.Reset).
+func (h *lifecycleHooks) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cancelHooks = nil
+	h.cancelFired = false
+	h.cancelOnce = sync.Once{}
+	h.watchOnce = sync.Once{}
+	h.cancelQueue = hookQueue{}
+	h.doneHooks = nil
+	h.doneFired = false
+	h.doneQueue = hookQueue{}
+}
+
+// OnCancel registers f as a hook which is run when the Group's context is canceled: when any task failed, the parent context is canceled, or Wait() finished.
+//
+// Cancel hooks are run in LIFO order (the last registered runs first), one at a time.
+// They always finish before any hook registered by OnDone starts, even if they are registered late from other goroutines; hooks registered after done hooks have started run after them instead.
+// If the Group has already been canceled, f is run immediately, or right after cancel hooks running at the moment (possibly on the goroutine running them), so that hooks never overlap.
+func (pg *Group) OnCancel(f func()) {
+	pg.OnCancelCause(func(error) { f() })
+}
+
+// OnCancelCause is like OnCancel, but f receives the cause of the cancellation (see context.Cause):
+// the error of the failed task, the cause of the cancellation of the parent context (e.g. context.DeadlineExceeded), or context.Canceled if it's canceled on the completion of Wait().
+//
+// It is useful for cleanup logic which depends on why the Group is shutting down.
+// Hooks registered by OnCancel and OnCancelCause are run in LIFO order as a whole.
+func (pg *Group) OnCancelCause(f func(err error)) {
+	h := &pg.hooks
+
+	h.mu.Lock()
+	if h.cancelFired {
+		q := &h.cancelQueue
+		if h.doneFired {
+			// done hooks may have started, so run f after them so that hooks never overlap.
+			q = &h.doneQueue
+		}
+		h.runQueue(q, func() { f(context.Cause(pg.ctx)) })
+		return
+	}
+	h.cancelHooks = append(h.cancelHooks, f)
+	h.mu.Unlock()
+
+	h.watchOnce.Do(func() {
+		context.AfterFunc(pg.ctx, pg.runCancelHooks)
+	})
+}
+
+// OnDone registers f as a hook which is run at the end of Wait(), after all tasks have completed and all cancel hooks have been run.
+//
+// Done hooks are run in FIFO order (the first registered runs first), one at a time.
+// If Wait() has already finished, f is run immediately, or right after done hooks running at the moment, like OnCancel.
+func (pg *Group) OnDone(f func()) {
+	h := &pg.hooks
+
+	h.mu.Lock()
+	if h.doneFired {
+		h.runQueue(&h.doneQueue, f)
+		return
+	}
+	h.doneHooks = append(h.doneHooks, f)
+	h.mu.Unlock()
+}
+
+// AfterCancel arranges to call f in its own goroutine after the Group's context is canceled, built on context.AfterFunc.
+// Calling the returned stop function unregisters f; it reports whether the call stopped f from being run.
+//
+// It is lighter than OnCancel for fire-and-forget notifications, but gives no guarantees about ordering with other hooks.
+func (pg *Group) AfterCancel(f func()) (stop func() bool) {
+	return context.AfterFunc(pg.ctx, f)
+}
+
+// Acquire opens a resource by open, and registers its Close as a cancel hook of the Group (see OnCancel).
+// The resource is released as soon as the Group is canceled, even if the task using it is interrupted mid-flight.
+//
+// Since the Group's context is always canceled at the end of Wait(), the resource is released by then at the latest. Hence the caller must not close the resource by itself.
+// If the Group has already been canceled, the resource is closed right away, and Acquire returns the cause of the cancellation (see context.Cause).
+// Errors returned from Close are ignored.
+func Acquire[R io.Closer](pg *Group, ctx context.Context, open func(context.Context) (R, error)) (R, error) {
+	var zero R
+	r, err := open(ctx)
+	if err != nil {
+		return zero, err
+	}
+	if pg.ctx.Err() != nil {
+		_ = r.Close()
+		return zero, context.Cause(pg.ctx)
+	}
+	pg.OnCancel(func() { _ = r.Close() })
+	return r, nil
+}
+
+// runCancelHooks runs cancel hooks only once. Concurrent callers block until all hooks have been run.
+func (pg *Group) runCancelHooks() {
+	h := &pg.hooks
+	h.cancelOnce.Do(func() {
+		cause := context.Cause(pg.ctx)
+
+		h.mu.Lock()
+		h.cancelFired = true
+		hooks := make([]func(), 0, len(h.cancelHooks))
+		for i := len(h.cancelHooks) - 1; i >= 0; i-- {
+			f := h.cancelHooks[i]
+			hooks = append(hooks, func() { f(cause) })
+		}
+		h.cancelHooks = nil
+		h.runQueue(&h.cancelQueue, hooks...)
+	})
+}
+
+// runDoneHooks runs done hooks, after waiting for cancel hooks registered late (and run by another goroutine) to finish.
+func (pg *Group) runDoneHooks() {
+	h := &pg.hooks
+
+	h.mu.Lock()
+	if h.idle == nil {
+		h.idle = sync.NewCond(&h.mu)
+	}
+	for h.cancelQueue.running || len(h.cancelQueue.pending) > 0 {
+		h.idle.Wait()
+	}
+	h.doneFired = true
+	hooks := h.doneHooks
+	h.doneHooks = nil
+	h.runQueue(&h.doneQueue, hooks...)
+}
+
+// WithOnFirstError registers f as a hook which is called exactly once, at the instant the Group transitions to the failed state, with the error that caused it (the error Wait returns).
+// In collect-all mode (see WithCollectAll), it's called with the first collected error.
+//
+// Unlike per-task error handling, it marks the single "point of no return" of the Group, which is useful for emitting one alert instead of per-task noise.
+// f is called synchronously from the goroutine reporting the error, after the Group has been canceled. It may report errors to the Group again (e.g. by Cancel).
+func WithOnFirstError(f func(err error)) Option {
+	return func(pg *Group) {
+		pg.onFirstError = f
+	}
+}
+
+// WithOnNoTasks registers f as a hook which is called at the end of Wait if no task has been launched on the Group, i.e. the Group did nothing.
+// f is called after cancel hooks, and before done hooks (see OnDone).
+func WithOnNoTasks(f func()) Option {
+	return func(pg *Group) {
+		pg.onNoTasks = f
+	}
+}
+
+// notifyFirstError calls the hook registered by WithOnFirstError, if any.
+func (pg *Group) notifyFirstError(err error) {
+	if pg.onFirstError != nil {
+		pg.onFirstError(err)
+	}
+}
@@ -0,0 +1,400 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(e string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func assertEvents(t *testing.T, want []string, got []string) {
+	t.Helper()
+	if fmt.Sprint(want) != fmt.Sprint(got) {
+		t.Fatalf("unexpected events (want: %v, got: %v)", want, got)
+	}
+}
+
+func TestHooks_order(t *testing.T) {
+	var l eventLog
+
+	pg := New()
+	pg.OnCancel(func() { l.add("cancel1") })
+	pg.OnCancel(func() { l.add("cancel2") })
+	pg.OnDone(func() { l.add("done1") })
+	pg.OnDone(func() { l.add("done2") })
+
+	GoAndForget(pg, delayedTask(100*time.Millisecond, func() error { return nil }))
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEvents(t, []string{"cancel2", "cancel1", "done1", "done2"}, l.get())
+
+	// hooks registered after Wait() are run immediately.
+	pg.OnCancel(func() { l.add("cancel3") })
+	pg.OnDone(func() { l.add("done3") })
+	assertEvents(t, []string{"cancel2", "cancel1", "done1", "done2", "cancel3", "done3"}, l.get())
+}
+
+func TestHooks_cancelOnFailure(t *testing.T) {
+	var l eventLog
+	canceled := make(chan struct{})
+
+	pg := New()
+	pg.OnCancel(func() { l.add("cancel"); close(canceled) })
+	pg.OnDone(func() { l.add("done") })
+
+	GoAndForget(pg, delayedTask(100*time.Millisecond, func() error { return errors.New("error!") }))
+	GoAndForget(pg, func(ctx context.Context) error {
+		// cancel hooks are run while other tasks are still running.
+		<-canceled
+		l.add("task")
+		return nil
+	})
+
+	if err := pg.Wait(); err == nil {
+		t.Fatal("error is expected")
+	}
+	assertEvents(t, []string{"cancel", "task", "done"}, l.get())
+}
+
+func TestHooks_cancelRacesWithWait(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var l eventLog
+
+		ctx, cancel := context.WithCancel(context.Background())
+		pg := WithContext(ctx)
+		pg.OnCancel(func() { time.Sleep(time.Millisecond); l.add("cancel1") })
+		pg.OnCancel(func() { l.add("cancel2") })
+		pg.OnDone(func() { l.add("done1") })
+		pg.OnDone(func() { l.add("done2") })
+
+		GoAndForget(pg, func(ctx context.Context) error { return nil })
+		// the parent context is canceled concurrently with the completion of Wait.
+		go cancel()
+
+		_ = pg.Wait()
+		assertEvents(t, []string{"cancel2", "cancel1", "done1", "done2"}, l.get())
+		cancel()
+	}
+}
+
+func TestOnCancelCause(t *testing.T) {
+	errExp := errors.New("error!")
+
+	tests := []struct {
+		name  string
+		setup func() *Group
+		want  error
+	}{
+		{
+			name: "task failure",
+			setup: func() *Group {
+				pg := New()
+				GoAndForget(pg, func(ctx context.Context) error { return errExp })
+				return pg
+			},
+			want: errExp,
+		},
+		{
+			name: "parent deadline",
+			setup: func() *Group {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				t.Cleanup(cancel)
+				pg := WithContext(ctx)
+				GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+				return pg
+			},
+			want: context.DeadlineExceeded,
+		},
+		{
+			name: "completion",
+			setup: func() *Group {
+				pg := New()
+				GoAndForget(pg, func(ctx context.Context) error { return nil })
+				return pg
+			},
+			want: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := tt.setup()
+
+			var got error
+			pg.OnCancelCause(func(err error) { got = err })
+			_ = pg.Wait()
+
+			if got != tt.want {
+				t.Fatalf("unexpected cause (want: %v, got: %v)", tt.want, got)
+			}
+		})
+	}
+}
+
+type fakeResource struct {
+	closed int32
+}
+
+func (r *fakeResource) Close() error {
+	atomic.AddInt32(&r.closed, 1)
+	return nil
+}
+
+func TestAcquire(t *testing.T) {
+	r := &fakeResource{}
+	acquired := make(chan struct{})
+
+	pg := New()
+	GoAndForget(pg, func(ctx context.Context) error {
+		if _, err := Acquire(pg, ctx, func(context.Context) (*fakeResource, error) { return r, nil }); err != nil {
+			return err
+		}
+		close(acquired)
+		// the task is interrupted by cancellation while using the resource.
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	GoAndForget(pg, func(ctx context.Context) error {
+		<-acquired
+		return errors.New("error!")
+	})
+
+	if err := pg.Wait(); err == nil {
+		t.Fatal("error is expected")
+	}
+	if r.closed != 1 {
+		t.Fatalf("resource should be closed exactly once: %d", r.closed)
+	}
+
+	// failure of opening the resource.
+	errExp := errors.New("open failed")
+	if _, err := Acquire(New(), context.Background(), func(context.Context) (*fakeResource, error) { return nil, errExp }); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAcquire_canceledGroup(t *testing.T) {
+	errExp := errors.New("canceled!")
+
+	pg := New()
+	pg.Cancel(errExp)
+
+	r := &fakeResource{}
+	got, err := Acquire(pg, context.Background(), func(context.Context) (*fakeResource, error) { return r, nil })
+	if err != errExp || got != nil {
+		t.Fatalf("Acquire on a canceled Group should fail: %v, %v", got, err)
+	}
+	if r.closed != 1 {
+		t.Fatalf("resource should be closed right away: %d", r.closed)
+	}
+	pg.Wait()
+	if r.closed != 1 {
+		t.Fatalf("resource should be closed exactly once: %d", r.closed)
+	}
+}
+
+func TestAfterCancel(t *testing.T) {
+	pg := New()
+
+	notified := make(chan struct{})
+	pg.AfterCancel(func() { close(notified) })
+	stop := pg.AfterCancel(func() { t.Error("stopped function should not be called") })
+	if !stop() {
+		t.Fatal("stop should report that it stopped the function")
+	}
+
+	GoAndForget(pg, func(ctx context.Context) error { return errors.New("error!") })
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("function should be called after cancellation")
+	}
+	_ = pg.Wait()
+}
+
+func TestWithOnFirstError(t *testing.T) {
+	for _, collectAll := range []bool{false, true} {
+		var (
+			calls atomic.Int32
+			got   error
+		)
+		opts := []Option{WithOnFirstError(func(err error) {
+			calls.Add(1)
+			got = err
+		})}
+		if collectAll {
+			opts = append(opts, WithCollectAll())
+		}
+		pg := New(opts...)
+
+		errFirst := errors.New("first")
+		GoAndForget(pg, delayedTask(10*time.Millisecond, func() error { return errFirst }))
+		GoAndForget(pg, delayedTask(30*time.Millisecond, func() error { return errors.New("second") }))
+		GoAndForget(pg, delayedTask(30*time.Millisecond, func() error { return nil }))
+
+		if err := pg.Wait(); !errors.Is(err, errFirst) {
+			t.Fatalf("unexpected error (collectAll: %v): %v", collectAll, err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Fatalf("hook should be called exactly once (collectAll: %v): %d", collectAll, n)
+		}
+		if got != errFirst {
+			t.Fatalf("unexpected error passed to hook (collectAll: %v): %v", collectAll, got)
+		}
+	}
+}
+
+func TestWithOnFirstError_reentrant(t *testing.T) {
+	for _, collectAll := range []bool{false, true} {
+		var pg *Group
+		opts := []Option{WithOnFirstError(func(error) {
+			// reporting an error to the Group again from the hook must not deadlock.
+			pg.Cancel(nil)
+		})}
+		if collectAll {
+			opts = append(opts, WithCollectAll())
+		}
+		pg = New(opts...)
+
+		errExp := errors.New("error!")
+		GoAndForget(pg, func(context.Context) error { return errExp })
+
+		done := make(chan error, 1)
+		go func() { done <- pg.Wait() }()
+		select {
+		case err := <-done:
+			if !errors.Is(err, errExp) {
+				t.Fatalf("unexpected error (collectAll: %v): %v", collectAll, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("hook calling Cancel deadlocked (collectAll: %v)", collectAll)
+		}
+	}
+}
+
+func TestWithOnNoTasks(t *testing.T) {
+	var calls atomic.Int32
+	pg := New(WithOnNoTasks(func() { calls.Add(1) }))
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatal("hook should be called if no task has been launched")
+	}
+
+	calls.Store(0)
+	pg = New(WithOnNoTasks(func() { calls.Add(1) }))
+	GoAndForget(pg, func(context.Context) error { return nil })
+	pg.Wait()
+	if calls.Load() != 0 {
+		t.Fatal("hook should not be called if any task has been launched")
+	}
+}
+
+func TestOnCancel_lateRegistrationDoesNotOverlap(t *testing.T) {
+	pg := New()
+
+	var (
+		running atomic.Int32
+		overlap atomic.Bool
+	)
+	hook := func(f func()) func() {
+		return func() {
+			if running.Add(1) > 1 {
+				overlap.Store(true)
+			}
+			f()
+			running.Add(-1)
+		}
+	}
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	pg.OnCancel(hook(func() {
+		close(entered)
+		<-release
+	}))
+	pg.Cancel(nil)
+	<-entered
+
+	// registered while the first hook is blocked
+	lateDone := make(chan struct{})
+	registered := make(chan struct{})
+	go func() {
+		pg.OnCancel(hook(func() { close(lateDone) }))
+		close(registered)
+	}()
+
+	select {
+	case <-lateDone:
+		t.Fatal("late hook should not run while another hook is running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-registered
+	<-lateDone
+
+	pg.Wait()
+	if overlap.Load() {
+		t.Fatal("hooks should never overlap")
+	}
+}
+
+func TestOnDone_waitsForLateCancelHooks(t *testing.T) {
+	pg := New()
+
+	earlyDone := make(chan struct{})
+	pg.OnCancel(func() { close(earlyDone) })
+
+	var slowRunning, overlap atomic.Bool
+	pg.OnDone(func() {
+		if slowRunning.Load() {
+			overlap.Store(true)
+		}
+	})
+
+	slowStarted, slowDone := make(chan struct{}), make(chan struct{})
+	GoAndForget(pg, func(context.Context) error { return errors.New("error!") })
+	GoAndForget(pg, func(context.Context) error {
+		<-slowStarted
+		return nil
+	})
+
+	// registered after the cancel hooks have been run, so that it runs on its own goroutine
+	<-earlyDone
+	go pg.OnCancel(func() {
+		slowRunning.Store(true)
+		close(slowStarted)
+		time.Sleep(20 * time.Millisecond)
+		slowRunning.Store(false)
+		close(slowDone)
+	})
+
+	pg.Wait()
+	<-slowDone
+	if overlap.Load() {
+		t.Fatal("done hooks should not run while a cancel hook is running")
+	}
+}
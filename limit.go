@@ -0,0 +1,374 @@
+package pgroup
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SetLimit limits the number of active tasks in the Group to at most n. A negative value indicates no limit.
+//
+// Any subsequent call to Go (and other functions launching tasks) will block until it can launch a task without exceeding the limit.
+//
+// The limit must not be modified while any tasks in the Group are active.
+func (pg *Group) SetLimit(n int) {
+	if n < 0 {
+		pg.limiter = nil
+		return
+	}
+	if pg.limiter != nil && pg.limiter.inUse() != 0 {
+		panic(fmt.Errorf("pgroup: modify limit while %v tasks are still active", pg.limiter.inUse()))
+	}
+	if pg.fairLimiter {
+		pg.limiter = newFIFOLimiter(n)
+	} else {
+		pg.limiter = make(chanLimiter, n)
+	}
+}
+
+// LimiterSaturated reports whether all tokens of the limiter set by SetLimit are currently in use, i.e. launching a new task would block.
+// It always reports false if no limit is set.
+//
+// It signals that the Group is the bottleneck, which is useful for autoscaling decisions.
+func (pg *Group) LimiterSaturated() bool {
+	if pg.limiter == nil {
+		return false
+	}
+	return pg.limiter.inUse() >= pg.limiter.size()
+}
+
+// limiterBalance returns the number of tokens acquired but not released yet. It should be 0 after Wait() returned, whatever paths tasks exited by.
+// It's intended to be used in tests for detecting leaks of tokens.
+func (pg *Group) limiterBalance() int {
+	n := 0
+	if pg.limiter != nil {
+		n += pg.limiter.inUse()
+	}
+
+	pg.classMu.RLock()
+	defer pg.classMu.RUnlock()
+	for _, l := range pg.classLimits {
+		n += l.inUse()
+	}
+	return n
+}
+
+// SetClassLimit limits the number of active tasks of the class (see GoClassLimited) to at most n, independently of other classes. A negative value removes the limit of the class.
+// The limit of the class applies in addition to the limit of the whole Group set by SetLimit.
+//
+// The limit of a class must not be modified while any tasks of the class are active.
+func (pg *Group) SetClassLimit(class string, n int) {
+	pg.classMu.Lock()
+	defer pg.classMu.Unlock()
+
+	if l, ok := pg.classLimits[class]; ok && l.inUse() != 0 {
+		panic(fmt.Errorf("pgroup: modify limit of class %q while %v tasks are still active", class, l.inUse()))
+	}
+	if n < 0 {
+		delete(pg.classLimits, class)
+		return
+	}
+	if pg.classLimits == nil {
+		pg.classLimits = make(map[string]chanLimiter)
+	}
+	pg.classLimits[class] = make(chanLimiter, n)
+}
+
+// classLimiter returns the limiter of the class of the task, or nil if the class has no limit.
+func (pg *Group) classLimiter(info *taskInfo) limiter {
+	if info == nil || info.class == "" {
+		return nil
+	}
+	pg.classMu.RLock()
+	defer pg.classMu.RUnlock()
+
+	if l, ok := pg.classLimits[info.class]; ok {
+		return l
+	}
+	return nil
+}
+
+// acquire blocks until a token for a new task is available.
+// The token of the class is acquired before the one of the Group, so that tasks blocked by the limit of their class don't hold tokens of the Group.
+func (pg *Group) acquire(info *taskInfo) {
+	if cl := pg.classLimiter(info); cl != nil {
+		cl.acquire()
+	}
+	pg.acquireGroup(info)
+}
+
+// acquireGroup blocks until a token of the limiter of the Group is available.
+func (pg *Group) acquireGroup(info *taskInfo) {
+	if pg.limiter == nil {
+		return
+	}
+	if pg.limiter.tryAcquire() {
+		return
+	}
+	if pg.onSaturated != nil {
+		pg.onSaturated()
+	}
+	if cl, ok := pg.limiter.(*fifoLimiter); ok && info != nil && info.class != "" {
+		cl.acquireClass(info.class, info.weight)
+		return
+	}
+	pg.limiter.acquire()
+}
+
+// tryAcquire acquires a token for a new task only if it's immediately available.
+func (pg *Group) tryAcquire() bool {
+	if pg.limiter == nil {
+		return true
+	}
+	return pg.limiter.tryAcquire()
+}
+
+// release releases a token acquired by acquire. It must be deferred by the goroutine running the task, so that it happens on every path the task exits by.
+func (pg *Group) release() {
+	if pg.limiter != nil {
+		pg.limiter.release()
+	}
+}
+
+// releaseClass releases a token of the class of the task acquired by acquire. It must be deferred like release.
+func (pg *Group) releaseClass(info *taskInfo) {
+	if cl := pg.classLimiter(info); cl != nil {
+		cl.release()
+	}
+}
+
+// limiter is a counting semaphore which limits the number of active tasks.
+type limiter interface {
+	acquire()
+	tryAcquire() bool
+	release()
+	// number of tokens currently acquired.
+	inUse() int
+	// total number of tokens.
+	size() int
+}
+
+// chanLimiter is the default limiter implemented by a buffered channel.
+// It doesn't guarantee any order in which blocked acquirers get tokens.
+type chanLimiter chan struct{}
+
+func (l chanLimiter) acquire() {
+	l <- struct{}{}
+}
+
+func (l chanLimiter) tryAcquire() bool {
+	select {
+	case l <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l chanLimiter) release() {
+	<-l
+}
+
+func (l chanLimiter) inUse() int {
+	return len(l)
+}
+
+func (l chanLimiter) size() int {
+	return cap(l)
+}
+
+// fifoLimiter is a limiter which hands tokens to blocked acquirers in FIFO order, using queues of tickets.
+//
+// Acquirers may be tagged with classes (see GoClass). Among waiters of different classes, tokens are handed to classes in proportion to their weights (by smooth weighted round-robin), and in FIFO order within each class.
+// Untagged acquirers belong to the class "" with weight 1, so the limiter is purely FIFO if no class is used.
+type fifoLimiter struct {
+	mu      sync.Mutex
+	cap     int
+	used    int
+	waiting int
+
+	classes map[string]*classQueue
+	// classes in the order of their first appearance, to break ties deterministically.
+	order []*classQueue
+}
+
+// classQueue is a queue of waiters of a class.
+type classQueue struct {
+	weight  int
+	current int
+	waiters list.List // of chan struct{}
+}
+
+func newFIFOLimiter(n int) *fifoLimiter {
+	return &fifoLimiter{cap: n, classes: make(map[string]*classQueue)}
+}
+
+func (l *fifoLimiter) acquire() {
+	l.acquireClass("", 1)
+}
+
+// acquireClass acquires a token as an acquirer of the class with the weight.
+// The weight of the class is updated to the latest value.
+func (l *fifoLimiter) acquireClass(class string, weight int) {
+	if weight < 1 {
+		weight = 1
+	}
+
+	l.mu.Lock()
+	if l.used < l.cap && l.waiting == 0 {
+		l.used++
+		l.mu.Unlock()
+		return
+	}
+	q, ok := l.classes[class]
+	if !ok {
+		q = &classQueue{}
+		l.classes[class] = q
+		l.order = append(l.order, q)
+	}
+	q.weight = weight
+
+	ticket := make(chan struct{})
+	q.waiters.PushBack(ticket)
+	l.waiting++
+	l.mu.Unlock()
+
+	// the token is handed over by release.
+	<-ticket
+}
+
+func (l *fifoLimiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.used < l.cap && l.waiting == 0 {
+		l.used++
+		return true
+	}
+	return false
+}
+
+func (l *fifoLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.waiting == 0 {
+		l.used--
+		return
+	}
+
+	// hand over the token to the oldest waiter of the class selected. l.used is unchanged.
+	q := l.nextClass()
+	close(q.waiters.Remove(q.waiters.Front()).(chan struct{}))
+	l.waiting--
+}
+
+// nextClass selects the class to which the next token is handed, among classes with waiters, by smooth weighted round-robin.
+func (l *fifoLimiter) nextClass() *classQueue {
+	var (
+		selected *classQueue
+		total    int
+	)
+	for _, q := range l.order {
+		if q.waiters.Len() == 0 {
+			continue
+		}
+		q.current += q.weight
+		total += q.weight
+		if selected == nil || q.current > selected.current {
+			selected = q
+		}
+	}
+	selected.current -= total
+	return selected
+}
+
+func (l *fifoLimiter) inUse() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+func (l *fifoLimiter) size() int {
+	return l.cap
+}
+
+// GoClass is like Go, but launches the task as a member of the class with the weight, for multi-tenant fan-outs.
+//
+// When the Group uses the fair limiter (see WithFairLimiter) and the limit is reached, blocked launches of different classes get tokens in proportion to their weights, so that a burst of a class doesn't starve others.
+// Without the limit, it's equivalent to Go. The limit of the class set by SetClassLimit also applies.
+//
+// Since the default limiter doesn't distinguish classes, it panics if weight is greater than 1 while the Group has the limit without WithFairLimiter, rather than ignoring the weight silently.
+// It also panics if f is nil.
+func GoClass[T any](pg *Group, class string, weight int, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	if _, fair := pg.limiter.(*fifoLimiter); weight > 1 && pg.limiter != nil && !fair {
+		panic(unfairWeightMsg)
+	}
+	p := newPromise[T](pg)
+
+	task, settle := promiseTask(p, f)
+	pg.launch(&taskInfo{index: -1, class: class, weight: weight}, task, settle)
+
+	return p
+}
+
+// unfairWeightMsg is the panic message on an attempt to launch a task of a weighted class on a Group whose limiter doesn't respect weights.
+const unfairWeightMsg = "pgroup: weighted class requires WithFairLimiter"
+
+// GoClassLimited is like Go, but launches the task as a member of the class, whose number of active tasks is limited by SetClassLimit.
+// It lets tasks hitting different downstreams have their own limits within a Group, e.g. 5 for a database and 50 for an HTTP API.
+//
+// Without the limit of the class, it's equivalent to Go (subject to the limit of the Group).
+func GoClassLimited[T any](pg *Group, class string, f func(ctx context.Context) (T, error)) *Promise[T] {
+	return GoClass(pg, class, 1, f)
+}
+
+// TryGo launches the given function in a new goroutine only if the number of active tasks in the Group is currently below the limit set by SetLimit, the rate limit (see WithRateLimit) allows it and the Group is not paused, like Go.
+// It reports whether the task was launched; if not, the returned Promise is nil.
+// Without the limit, it always launches the task.
+//
+// The exception is a launch skipped by WithFailFastOnCanceledParent with FailFastSkip: it reports false, along with the already-failed Promise holding the error of the parent context.
+//
+// It panics if f is nil.
+func TryGo[T any](pg *Group, f func(ctx context.Context) (T, error)) (*Promise[T], bool) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	p := newPromise[T](pg)
+	task, settle := promiseTask(p, f)
+
+	if pg.skipLaunch(settle) {
+		return p, false
+	}
+	if pg.gate.isPaused() || !pg.tryAcquire() {
+		p.putBack()
+		return nil, false
+	}
+	if !pg.rate.tryTake() {
+		pg.release()
+		p.putBack()
+		return nil, false
+	}
+	pg.start(pg.captureLaunchSite(nil), task, settle)
+
+	return p, true
+}
+
+// TryGoBatch launches as many of fs as the limiter allows right now, like calling TryGo for each of them in order.
+// It returns Promises of the launched tasks, and the rest of fs which were not launched (deferred) for the caller to retry later.
+//
+// It gives explicit control over partial dispatch under a concurrency limit.
+func TryGoBatch[T any](pg *Group, fs []func(context.Context) (T, error)) (launched []*Promise[T], deferred []func(context.Context) (T, error)) {
+	for i, f := range fs {
+		p, ok := TryGo(pg, f)
+		if !ok {
+			return launched, fs[i:]
+		}
+		launched = append(launched, p)
+	}
+	return launched, nil
+}
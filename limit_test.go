@@ -0,0 +1,301 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetLimit(t *testing.T) {
+	pg := New()
+	pg.SetLimit(2)
+
+	var active, maxActive int32
+	task := func(ctx context.Context) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	}
+
+	for i := 0; i < 6; i++ {
+		GoAndForget(pg, task)
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxActive != 2 {
+		t.Fatalf("unexpected max number of active tasks (want: %v, got: %v)", 2, maxActive)
+	}
+}
+
+func TestTryGo(t *testing.T) {
+	pg := New()
+	pg.SetLimit(1)
+
+	block := make(chan struct{})
+	p1, ok := TryGo(pg, func(ctx context.Context) (int, error) { <-block; return 1, nil })
+	if !ok || p1 == nil {
+		t.Fatal("first task should be launched")
+	}
+
+	p2, ok := TryGo(pg, func(ctx context.Context) (int, error) { return 2, nil })
+	if ok || p2 != nil {
+		t.Fatal("second task should not be launched while the limit is reached")
+	}
+
+	close(block)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.Get() != 1 {
+		t.Fatalf("unexpected result (want: %v, got: %v)", 1, p1.Get())
+	}
+
+	// without limit, it always launches tasks.
+	pg2 := New()
+	for i := 0; i < 3; i++ {
+		if _, ok := TryGo(pg2, func(ctx context.Context) (int, error) { return 0, nil }); !ok {
+			t.Fatal("task should be launched without limit")
+		}
+	}
+	if err := pg2.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// skipped launches are not reported as launched.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pg3 := WithContext(ctx, WithFailFastOnCanceledParent(FailFastSkip))
+	p3, ok := TryGo(pg3, func(ctx context.Context) (int, error) { return 3, nil })
+	if ok || p3 == nil || p3.err != context.Canceled {
+		t.Fatalf("skipped task should not be reported as launched: %v, %v", ok, p3)
+	}
+	if err := pg3.Wait(); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithFairLimiter(t *testing.T) {
+	pg := New(WithFairLimiter())
+	pg.SetLimit(1)
+
+	block := make(chan struct{})
+	GoAndForget(pg, func(ctx context.Context) error { <-block; return nil })
+
+	// producers get blocked one by one.
+	var l eventLog
+	var producers sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		i := i
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			GoAndForget(pg, func(ctx context.Context) error { l.add(fmt.Sprint(i)); return nil })
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	close(block)
+	producers.Wait()
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEvents(t, []string{"0", "1", "2", "3", "4"}, l.get())
+}
+
+func TestLimiterSaturated(t *testing.T) {
+	var blocked int32
+	pg := New(WithOnLimiterSaturated(func() { atomic.AddInt32(&blocked, 1) }))
+
+	if pg.LimiterSaturated() {
+		t.Fatal("limiter should not be saturated without limit")
+	}
+
+	pg.SetLimit(2)
+	block := make(chan struct{})
+	task := func(ctx context.Context) error { <-block; return nil }
+
+	GoAndForget(pg, task)
+	if pg.LimiterSaturated() {
+		t.Fatal("limiter should not be saturated")
+	}
+	GoAndForget(pg, task)
+	if !pg.LimiterSaturated() {
+		t.Fatal("limiter should be saturated")
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(block)
+	}()
+	GoAndForget(pg, func(ctx context.Context) error { return nil })
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if blocked != 1 {
+		t.Fatalf("unexpected number of saturation hook calls: %d", blocked)
+	}
+	if pg.LimiterSaturated() {
+		t.Fatal("limiter should not be saturated after Wait")
+	}
+}
+
+func TestGoClass(t *testing.T) {
+	pg := New(WithFairLimiter())
+	pg.SetLimit(1)
+
+	block := make(chan struct{})
+	GoAndForget(pg, func(ctx context.Context) error { <-block; return nil })
+
+	var l eventLog
+	var producers sync.WaitGroup
+	launch := func(class string, weight int) {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			GoClass(pg, class, weight, func(ctx context.Context) (struct{}, error) { l.add(class); return struct{}{}, nil })
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	// a burst of class "a" is queued before class "b".
+	for i := 0; i < 6; i++ {
+		launch("a", 3)
+	}
+	for i := 0; i < 2; i++ {
+		launch("b", 1)
+	}
+
+	close(block)
+	producers.Wait()
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// tokens are handed to "a" and "b" in proportion to 3:1.
+	assertEvents(t, []string{"a", "a", "b", "a", "a", "a", "b", "a"}, l.get())
+
+	// weights are not ignored silently by the default limiter
+	pg = New()
+	pg.SetLimit(1)
+	assertPanics(t, unfairWeightMsg, func() {
+		GoClass(pg, "a", 3, func(ctx context.Context) (struct{}, error) { return struct{}{}, nil })
+	})
+	GoClass(pg, "a", 1, func(ctx context.Context) (struct{}, error) { return struct{}{}, nil })
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTryGoBatch(t *testing.T) {
+	pg := New()
+	pg.SetLimit(2)
+
+	block := make(chan struct{})
+	fs := make([]func(context.Context) (int, error), 5)
+	for i := range fs {
+		i := i
+		fs[i] = func(ctx context.Context) (int, error) { <-block; return i, nil }
+	}
+
+	launched, deferred := TryGoBatch(pg, fs)
+	if len(launched) != 2 || len(deferred) != 3 {
+		t.Fatalf("unexpected number of launched/deferred tasks: %d, %d", len(launched), len(deferred))
+	}
+
+	close(block)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, p := range launched {
+		if p.Get() != i {
+			t.Fatalf("unexpected result (want: %v, got: %v)", i, p.Get())
+		}
+	}
+}
+
+func TestLimiterBalance(t *testing.T) {
+	for _, fair := range []bool{false, true} {
+		opts := []Option{WithPanicHandler(func(any, []byte) error { return errors.New("panicked") }), WithCollectAll()}
+		if fair {
+			opts = append(opts, WithFairLimiter())
+		}
+		pg := New(opts...)
+		pg.SetLimit(2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		for i := 0; i < 3; i++ {
+			GoAndForget(pg, func(context.Context) error { panic("boom") })
+			GoAndForget(pg, func(context.Context) error { return errors.New("error!") })
+			GoAndForget(pg, func(context.Context) error { <-ctx.Done(); return ctx.Err() })
+			Go(pg, func(context.Context) (int, error) { return 0, nil })
+		}
+		_ = pg.Wait()
+
+		if n := pg.limiterBalance(); n != 0 {
+			t.Fatalf("tokens are leaked (fair: %v): %d", fair, n)
+		}
+	}
+}
+
+func TestSetClassLimit(t *testing.T) {
+	pg := New()
+	pg.SetClassLimit("db", 2)
+	pg.SetClassLimit("http", 5)
+
+	var mu sync.Mutex
+	active := make(map[string]int)
+	maxActive := make(map[string]int)
+	task := func(class string) func(context.Context) (int, error) {
+		return func(context.Context) (int, error) {
+			mu.Lock()
+			active[class]++
+			if active[class] > maxActive[class] {
+				maxActive[class] = active[class]
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			active[class]--
+			mu.Unlock()
+			return 0, nil
+		}
+	}
+
+	var producers sync.WaitGroup
+	for _, class := range []string{"db", "http"} {
+		class := class
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i := 0; i < 10; i++ {
+				GoClassLimited(pg, class, task(class))
+			}
+		}()
+	}
+	producers.Wait()
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxActive["db"] != 2 || maxActive["http"] != 5 {
+		t.Fatalf("unexpected max active tasks per class: %v", maxActive)
+	}
+	if n := pg.limiterBalance(); n != 0 {
+		t.Fatalf("tokens leaked: %d", n)
+	}
+}
@@ -0,0 +1,26 @@
+package pgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// Lock acquires the mutex scoped to the Group, which standardizes access to shared state across tasks of the Group.
+// It blocks until the mutex is acquired or ctx is done; in the latter case, it returns ctx.Err() and the mutex is not acquired.
+//
+// On success, it returns a function to release the mutex. Calling it more than once is a no-op.
+func (pg *Group) Lock(ctx context.Context) (unlock func(), err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case pg.mu <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-pg.mu })
+	}, nil
+}
@@ -0,0 +1,56 @@
+package pgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGroup_Lock(t *testing.T) {
+	pg := New()
+
+	counter := 0
+	for i := 0; i < 10; i++ {
+		GoAndForget(pg, func(ctx context.Context) error {
+			unlock, err := pg.Lock(ctx)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			v := counter
+			time.Sleep(time.Millisecond)
+			counter = v + 1
+			return nil
+		})
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counter != 10 {
+		t.Fatalf("tasks should be mutually exclusive: %d", counter)
+	}
+}
+
+func TestGroup_Lock_canceled(t *testing.T) {
+	pg := New()
+
+	unlock, err := pg.Lock(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pg.Lock(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unlock()
+	unlock() // no-op
+	unlock2, err := pg.Lock(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unlock2()
+}
@@ -0,0 +1,72 @@
+package pgroup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger returns a new Group with the "parent context", like WithContext, which logs lifecycle of its tasks via the logger.
+// Starts and ends of tasks are logged at debug level, and failures of tasks are logged at error level. Each record has the duration of the task as an attribute, as well as the name of the task if it's launched by GoNamed or GoAndForgetNamed.
+//
+// If logger is nil, the logger attached to ctx by ContextWithLogger is used, or the default logger (slog.Default()) if there is none.
+func WithLogger(ctx context.Context, logger *slog.Logger) *Group {
+	if logger == nil {
+		logger = loggerFromContext(ctx)
+	}
+	pg := WithContext(ctx)
+	pg.logger = logger
+	return pg
+}
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx which carries logger, to be used by Groups created by WithLogger with ctx (or any context derived from it) and no explicit logger.
+// It lets request-scoped loggers (e.g. with a request ID) flow to Groups created deep in call stacks.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFromContext returns the logger attached to ctx by ContextWithLogger, or the default logger if there is none.
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+func (pg *Group) logTaskStart(info *taskInfo) {
+	if pg.logger == nil {
+		return
+	}
+	pg.logger.LogAttrs(pg.ctx, slog.LevelDebug, "pgroup: task started", pg.logAttrs(info)...)
+}
+
+func (pg *Group) logTaskEnd(info *taskInfo, dur time.Duration, err error) {
+	if pg.logger == nil {
+		return
+	}
+	attrs := append(pg.logAttrs(info), slog.Duration("duration", dur))
+	if err != nil {
+		pg.logger.LogAttrs(pg.ctx, slog.LevelError, "pgroup: task failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	pg.logger.LogAttrs(pg.ctx, slog.LevelDebug, "pgroup: task finished", attrs...)
+}
+
+// logAttrs returns attributes identifying the Group and the task.
+func (pg *Group) logAttrs(info *taskInfo) []slog.Attr {
+	var attrs []slog.Attr
+	if pg.name != "" {
+		attrs = append(attrs, slog.String("group", pg.name))
+	}
+	if info != nil {
+		if info.name != "" {
+			attrs = append(attrs, slog.String("task", info.name))
+		}
+		if info.index >= 0 {
+			attrs = append(attrs, slog.Int("index", info.index))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,56 @@
+package pgroup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	task := delayedTask(100*time.Millisecond, func() error { return nil })
+	etask := delayedTask(200*time.Millisecond, func() error { return errors.New("error!") })
+
+	pg := WithLogger(context.Background(), logger)
+
+	GoAndForget(pg, task)
+	GoAndForget(pg, etask)
+
+	if err := pg.Wait(); err == nil {
+		t.Fatal("error is expected")
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "pgroup: task started"); n != 2 {
+		t.Fatalf("unexpected number of start logs: %d\n%s", n, out)
+	}
+	if n := strings.Count(out, "pgroup: task finished"); n != 1 {
+		t.Fatalf("unexpected number of finish logs: %d\n%s", n, out)
+	}
+	if !strings.Contains(out, "level=ERROR msg=\"pgroup: task failed\"") || !strings.Contains(out, "error=error!") {
+		t.Fatalf("failure of task is not logged:\n%s", out)
+	}
+}
+
+func TestWithLogger_fromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx := ContextWithLogger(context.Background(), logger.With("request", "r1"))
+	pg := WithLogger(ctx, nil)
+	GoAndForget(pg, func(context.Context) error { return nil })
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "request=r1"); n != 2 {
+		t.Fatalf("logger from the context should be used:\n%s", out)
+	}
+}
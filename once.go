@@ -0,0 +1,59 @@
+package pgroup
+
+import (
+	"context"
+	"fmt"
+)
+
+// GoOnce is like Go, but launches f only once per key: subsequent calls with the same key return the Promise of the task launched first, without launching f.
+// It memoizes results of expensive tasks shared by many callers. Failed results are memoized as well; use Invalidate to retry them.
+//
+// The memoized Promises are cleared by Reset, unless the Group is created with WithPersistentCache.
+// It panics if f is nil, or if the key has been used with a different result type.
+func GoOnce[T any](pg *Group, key string, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	m := &pg.once
+	m.mu.Lock()
+	if v, ok := m.ps[key]; ok {
+		m.mu.Unlock()
+		p, ok := v.(*Promise[T])
+		if !ok {
+			panic(fmt.Sprintf("pgroup: GoOnce key %q used with a different result type", key))
+		}
+		return p
+	}
+	p := newPromise[T](pg)
+	if m.ps == nil {
+		m.ps = make(map[string]any)
+	}
+	m.ps[key] = p
+	m.mu.Unlock()
+
+	task, settle := promiseTask(p, f)
+	pg.launch(nil, task, settle)
+
+	return p
+}
+
+// Invalidate forgets the Promise memoized by GoOnce for the key, so that the next GoOnce with the key launches the task again.
+// The forgotten Promise is left unaffected.
+func (pg *Group) Invalidate(key string) {
+	m := &pg.once
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.ps, key)
+}
+
+// WithPersistentCache makes the Group keep results memoized by GoOnce across Reset, so that they are reused in later rounds of the Group.
+// Use Invalidate to drop stale entries.
+//
+// Memoized Promises must not be released to the pool (see Promise.Release) while they are cached.
+func WithPersistentCache() Option {
+	return func(pg *Group) {
+		pg.persistentCache = true
+	}
+}
@@ -0,0 +1,81 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGoOnce(t *testing.T) {
+	pg := New()
+
+	var n atomic.Int32
+	f := func(context.Context) (int, error) { return int(n.Add(1)), nil }
+
+	p1 := GoOnce(pg, "k", f)
+	p2 := GoOnce(pg, "k", f)
+	p3 := GoOnce(pg, "other", f)
+	if p1 != p2 || p1 == p3 {
+		t.Fatal("GoOnce should return the memoized Promise for the same key")
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.Load() != 2 {
+		t.Fatalf("unexpected number of runs: %d", n.Load())
+	}
+
+	assertPanics(t, `pgroup: GoOnce key "k" used with a different result type`, func() {
+		GoOnce(pg, "k", func(context.Context) (string, error) { return "", nil })
+	})
+}
+
+func TestReset(t *testing.T) {
+	pg := New(WithWorkers(2))
+
+	var n atomic.Int32
+	f := func(context.Context) (int, error) { return int(n.Add(1)), nil }
+
+	assertPanics(t, "pgroup: Reset before Wait finished", pg.Reset)
+
+	errFoo := errors.New("foo")
+	GoOnce(pg, "k", f)
+	GoAndForget(pg, func(context.Context) error { return errFoo })
+	if err := pg.Wait(); err != errFoo {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pg.Reset()
+	if pg.IsDone() || pg.ctx.Err() != nil || pg.Stats() != (Stats{}) {
+		t.Fatal("Group should be fresh after Reset")
+	}
+	p := GoOnce(pg, "k", f)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() != 2 {
+		t.Fatalf("memoized results should be cleared by Reset: %d", p.Get())
+	}
+}
+
+func TestWithPersistentCache(t *testing.T) {
+	pg := New(WithPersistentCache())
+
+	var n atomic.Int32
+	f := func(context.Context) (int, error) { return int(n.Add(1)), nil }
+
+	p := GoOnce(pg, "k", f)
+	pg.Wait()
+	pg.Reset()
+
+	if GoOnce(pg, "k", f) != p {
+		t.Fatal("memoized results should be kept across Reset")
+	}
+	pg.Invalidate("k")
+	p2 := GoOnce(pg, "k", f)
+	pg.Wait()
+	if p2 == p || p2.Get() != 2 {
+		t.Fatalf("invalidated key should launch the task again: %d", p2.Get())
+	}
+}
@@ -0,0 +1,109 @@
+package pgroup
+
+import "time"
+
+// Option configures the behavior of a Group. Options are passed to New or WithContext.
+type Option func(*Group)
+
+// FailFastMode specifies how a Group behaves on launching tasks if its parent context had already been canceled when the Group was created.
+type FailFastMode int
+
+const (
+	// FailFastSkip makes the Group skip spawning tasks. Go returns an already-failed Promise holding the error of the parent context, and it is also reported as the error of the Group.
+	FailFastSkip FailFastMode = iota + 1
+
+	// FailFastPanic makes launching tasks panic, for "strict" usages where launching tasks on the doomed Group is considered to be a bug.
+	FailFastPanic
+)
+
+// canceledParentMsg is the panic message on launching tasks with FailFastPanic mode.
+const canceledParentMsg = "pgroup: launching task on a Group whose parent context had been canceled"
+
+// WithFailFastOnCanceledParent makes the Group avoid launching doomed goroutines if the parent context passed to WithContext had already been canceled.
+// By default, tasks are launched even in such case and they will (typically) fail immediately.
+func WithFailFastOnCanceledParent(mode FailFastMode) Option {
+	return func(pg *Group) {
+		pg.failFast = mode
+	}
+}
+
+// WithCollectAll makes the Group run in "collect-all" mode.
+// In this mode, an error returned from a task doesn't cancel other tasks, and Wait returns errors of all failed tasks joined into an error (see errors.Join).
+// Tasks are still canceled when the parent context is canceled.
+func WithCollectAll() Option {
+	return func(pg *Group) {
+		pg.collectAll = true
+	}
+}
+
+// WithMaxCollectedErrors limits the number of errors retained in collect-all mode to the first n errors, to keep the size of the error bounded even if a huge number of tasks fail.
+// The number of dropped errors is reported in the message of the error, as a "... and N more" suffix.
+//
+// It has no effect unless the Group is in collect-all mode (see WithCollectAll). n <= 0 means no limit.
+func WithMaxCollectedErrors(n int) Option {
+	return func(pg *Group) {
+		if n < 0 {
+			n = 0
+		}
+		pg.errs.max = n
+	}
+}
+
+// WithFairLimiter makes the limiter set by SetLimit fair: producers blocked on launching tasks due to the limit get tokens in FIFO order, so that no producer starves.
+// The default limiter doesn't guarantee any order.
+func WithFairLimiter() Option {
+	return func(pg *Group) {
+		pg.fairLimiter = true
+	}
+}
+
+// WithOnLimiterSaturated registers f as a hook which is called every time launching a task has to block because all tokens of the limiter set by SetLimit are in use.
+// f is called from the goroutine launching the task, before it blocks.
+func WithOnLimiterSaturated(f func()) Option {
+	return func(pg *Group) {
+		pg.onSaturated = f
+	}
+}
+
+// WithLimit limits the number of active tasks in the Group to at most n, like calling SetLimit right after creating the Group.
+// It respects WithFairLimiter regardless of the order of options.
+func WithLimit(n int) Option {
+	return func(pg *Group) {
+		pg.initLimit = n
+		pg.hasInitLimit = true
+	}
+}
+
+// WithRecover makes the Group recover from panics in tasks and treat them as errors of the tasks. The errors are *PanicError carrying the recovered value and the stack trace.
+//
+// It is a shorthand of WithPanicHandler with a handler which returns *PanicError.
+func WithRecover() Option {
+	return WithPanicHandler(func(r any, stack []byte) error {
+		return &PanicError{Value: r, Stack: stack}
+	})
+}
+
+// WithRateLimit limits the rate of launching tasks in the Group to at most n tasks per the duration, allowing bursts of up to n tasks.
+// Go (and other functions launching tasks) block until launching a task is allowed by the rate limit; TryGo doesn't launch tasks if not allowed.
+//
+// It panics if n <= 0 or per <= 0.
+func WithRateLimit(n int, per time.Duration) Option {
+	if n <= 0 || per <= 0 {
+		panic("pgroup: invalid rate limit")
+	}
+	return func(pg *Group) {
+		pg.rate = newRateLimiter(n, per)
+	}
+}
+
+// WithFailureThreshold makes the Group tolerate failures of tasks until m tasks have failed: failures below the threshold are recorded but don't cancel other tasks.
+// Once m tasks have failed, the Group is canceled and its error is those m errors joined into an error (see errors.Join).
+// If the Group finishes with fewer failures than the threshold, Wait returns nil.
+//
+// Only errors returned from tasks count toward the threshold; the cancellation by Cancel or the parent context takes effect immediately.
+// It has no effect in collect-all mode (see WithCollectAll). m <= 1 means the default behavior (failing on the first error).
+func WithFailureThreshold(m int) Option {
+	return func(pg *Group) {
+		pg.failThreshold = m
+	}
+}
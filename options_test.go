@@ -0,0 +1,150 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithFailFastOnCanceledParent_skip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pg := WithContext(ctx, WithFailFastOnCanceledParent(FailFastSkip))
+
+	called := false
+	p := Go(pg, func(ctx context.Context) (int, error) {
+		called = true
+		return 42, nil
+	})
+
+	// the Promise should be already failed without Wait()-ing on the Group.
+	select {
+	case <-p.done:
+	default:
+		t.Fatal("promise should be settled immediately")
+	}
+	if p.err != context.Canceled {
+		t.Fatalf("unexpected error of promise: %v", p.err)
+	}
+
+	if err := pg.Wait(); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Fatal("task should not be run")
+	}
+	if s := pg.Stats(); s.Skipped != 1 || s.Launched != 0 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestWithFailFastOnCanceledParent_panic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pg := WithContext(ctx, WithFailFastOnCanceledParent(FailFastPanic))
+
+	assertPanics(t, canceledParentMsg, func() {
+		GoAndForget(pg, func(ctx context.Context) error { return nil })
+	})
+}
+
+func TestWithFailFastOnCanceledParent_notCanceled(t *testing.T) {
+	pg := New(WithFailFastOnCanceledParent(FailFastPanic))
+
+	p := Go(pg, func(ctx context.Context) (int, error) { return 42, nil })
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() != 42 {
+		t.Fatalf("unexpected result (want: %v, got: %v)", 42, p.Get())
+	}
+}
+
+func TestWithCollectAll(t *testing.T) {
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+
+	pg := New(WithCollectAll())
+
+	p := Go(pg, delayedResultTask(200*time.Millisecond, func() (int, error) { return 42, nil }))
+	GoAndForget(pg, delayedTask(100*time.Millisecond, func() error { return err1 }))
+	GoAndForget(pg, delayedTask(100*time.Millisecond, func() error { return err2 }))
+
+	err := pg.Wait()
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the error doesn't cancel other tasks.
+	if p.Get() != 42 {
+		t.Fatalf("unexpected result (want: %v, got: %v)", 42, p.Get())
+	}
+}
+
+func TestWithMaxCollectedErrors(t *testing.T) {
+	pg := New(WithCollectAll(), WithMaxCollectedErrors(2))
+
+	for i := 0; i < 5; i++ {
+		err := fmt.Errorf("error %d", i)
+		GoTo(pg, make(chan error, 1), func(ctx context.Context) error { return err })
+	}
+
+	err := pg.Wait()
+	if err == nil {
+		t.Fatal("error is expected")
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+		t.Fatalf("unexpected number of retained errors: %d", n)
+	}
+	if !strings.HasSuffix(err.Error(), "... and 3 more") {
+		t.Fatalf("unexpected error message: %q", err.Error())
+	}
+}
+
+func TestWithLimit(t *testing.T) {
+	// WithLimit should respect WithFairLimiter even if it comes first
+	pg := New(WithLimit(2), WithFairLimiter())
+
+	if _, ok := pg.limiter.(*fifoLimiter); !ok {
+		t.Fatalf("limiter should be fair: %T", pg.limiter)
+	}
+	if pg.limiter.size() != 2 {
+		t.Fatalf("unexpected limit: %d", pg.limiter.size())
+	}
+}
+
+func TestWithFailureThreshold(t *testing.T) {
+	err1, err2, err3 := errors.New("error 1"), errors.New("error 2"), errors.New("error 3")
+
+	// below the threshold, failures are tolerated
+	pg := New(WithFailureThreshold(3))
+	GoAndForget(pg, delayedTask(10*time.Millisecond, func() error { return err1 }))
+	GoAndForget(pg, delayedTask(20*time.Millisecond, func() error { return err2 }))
+	ok := Go(pg, delayedResultTask(40*time.Millisecond, func() (int, error) { return 1, nil }))
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok.Get() != 1 {
+		t.Fatal("tasks should keep running below the threshold")
+	}
+
+	// reaching the threshold cancels the Group
+	pg = New(WithFailureThreshold(3))
+	GoAndForget(pg, delayedTask(10*time.Millisecond, func() error { return err1 }))
+	GoAndForget(pg, delayedTask(20*time.Millisecond, func() error { return err2 }))
+	GoAndForget(pg, delayedTask(30*time.Millisecond, func() error { return err3 }))
+	slow := Go(pg, delayedResultTask(time.Second, func() (int, error) { return 1, nil }))
+
+	err := pg.Wait()
+	if !errors.Is(err, err1) || !errors.Is(err, err2) || !errors.Is(err, err3) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slow.err != context.Canceled {
+		t.Fatalf("the rest of tasks should be canceled: %v", slow.err)
+	}
+}
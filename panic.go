@@ -0,0 +1,68 @@
+package pgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a task which panicked, recovered by the Group configured by WithRecover.
+type PanicError struct {
+	// Value is the value recovered from the panic.
+	Value any
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("pgroup: task panicked: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// WithPanicHandler makes the Group recover from panics in tasks and handle them by h.
+// h is called with the recovered value and the stack trace of the panic. If h returns non-nil error, it is treated as the error of the task; if h returns nil, the panic is swallowed and the task is considered succeeded (with zero result value).
+//
+// h can also implement custom panic policies, e.g. incrementing a metric and re-panicking.
+// By default, panics in tasks are not recovered and crash the program.
+func WithPanicHandler(h func(r any, stack []byte) error) Option {
+	return func(pg *Group) {
+		pg.panicHandler = h
+	}
+}
+
+// WithIsolatedPanics makes the Group recover from panics in tasks, and isolate them: a panicking task fails only itself, with *PanicError as the error of its Promise.
+// The panic is not reported to the Group, so it doesn't cancel other tasks nor make Wait fail. Panics of tasks without Promises (e.g. GoAndForget) are only logged (see WithLogger).
+//
+// It suits best-effort batch jobs where one bad record shouldn't abort the batch, typically combined with WithCollectAll so that ordinary errors don't cancel other tasks either.
+// It takes precedence over WithPanicHandler and WithRecover.
+func WithIsolatedPanics() Option {
+	return func(pg *Group) {
+		pg.isolatePanics = true
+	}
+}
+
+// runTask runs the task, recovering from a panic in it if the panic handler is set or panics are isolated.
+// If the task panicked, it returns the error from the handler, or *isolatedError if panics are isolated.
+func (pg *Group) runTask(ctx context.Context, task func(ctx context.Context) error) (err error) {
+	if pg.panicHandler == nil && !pg.isolatePanics {
+		return task(ctx)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if pg.isolatePanics {
+				err = &isolatedError{err: &PanicError{Value: r, Stack: debug.Stack()}}
+				return
+			}
+			err = pg.panicHandler(r, debug.Stack())
+		}
+	}()
+	return task(ctx)
+}
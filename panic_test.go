@@ -0,0 +1,110 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWithPanicHandler(t *testing.T) {
+	var recovered []any
+	h := func(r any, stack []byte) error {
+		recovered = append(recovered, r)
+		if len(stack) == 0 {
+			t.Error("stack should be passed to the handler")
+		}
+		if r == "swallow" {
+			return nil
+		}
+		return fmt.Errorf("panic: %v", r)
+	}
+
+	pg := New(WithPanicHandler(h), WithCollectAll())
+	pg.SetLimit(1)
+
+	p1 := Go(pg, func(ctx context.Context) (int, error) { panic("boom") })
+	p2 := Go(pg, func(ctx context.Context) (int, error) { panic("swallow") })
+	p3 := Go(pg, func(ctx context.Context) (int, error) { return 42, nil })
+
+	err := pg.Wait()
+	if err == nil || err.Error() != "panic: boom" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recovered) != 2 {
+		t.Fatalf("unexpected recovered values: %v", recovered)
+	}
+
+	if p1.err == nil || p1.err.Error() != "panic: boom" {
+		t.Fatalf("unexpected error of panicked promise: %v", p1.err)
+	}
+	if p2.err != nil || p2.Get() != 0 {
+		t.Fatalf("swallowed panic should resolve the promise with zero value: %v, %v", p2.Get(), p2.err)
+	}
+	if p3.Get() != 42 {
+		t.Fatalf("unexpected result (want: %v, got: %v)", 42, p3.Get())
+	}
+}
+
+func TestWithPanicHandler_dependents(t *testing.T) {
+	errPanic := errors.New("panicked")
+	pg := New(WithPanicHandler(func(any, []byte) error { return errPanic }))
+
+	p := Go(pg, func(ctx context.Context) (int, error) { panic("boom") })
+	q := ThenGroup(p, func(ctx context.Context, pg *Group, v int) (int, error) { return v, nil })
+
+	if err := pg.Wait(); err != errPanic {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the dependent task doesn't hang on the panicked task.
+	if q.err != errPanic {
+		t.Fatalf("unexpected error of dependent promise: %v", q.err)
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	pg := New(WithRecover())
+
+	errBoom := errors.New("boom")
+	p := Go(pg, func(context.Context) (int, error) {
+		panic(errBoom)
+	})
+
+	_, err := p.await(context.Background())
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("error should be *PanicError: %v", err)
+	}
+	if len(pe.Stack) == 0 {
+		t.Fatal("stack trace should be captured")
+	}
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("error should wrap the panic value: %v", err)
+	}
+	if err := pg.Wait(); !errors.As(err, &pe) {
+		t.Fatalf("Wait should return *PanicError: %v", err)
+	}
+}
+
+func TestWithIsolatedPanics(t *testing.T) {
+	pg := New(WithIsolatedPanics(), WithCollectAll())
+
+	bad := Go(pg, func(context.Context) (int, error) {
+		panic("bad record")
+	})
+	good := Go(pg, func(ctx context.Context) (int, error) {
+		<-bad.done
+		return 1, ctx.Err()
+	})
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("isolated panic should not fail the Group: %v", err)
+	}
+	var pe *PanicError
+	if !errors.As(bad.err, &pe) || pe.Value != "bad record" {
+		t.Fatalf("error of the Promise should be *PanicError: %v", bad.err)
+	}
+	if good.Get() != 1 {
+		t.Fatal("siblings should continue")
+	}
+}
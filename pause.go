@@ -0,0 +1,63 @@
+package pgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// launchGate blocks launches of new tasks while a Group is paused.
+type launchGate struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{}
+}
+
+// Pause temporarily halts launches of new tasks without tearing down the Group.
+// While the Group is paused, Go (and other functions launching tasks) block until Resume is called; TryGo doesn't launch tasks.
+// Tasks already running are not affected.
+//
+// It is useful for throttling a pipeline when the downstream system signals backpressure.
+func (pg *Group) Pause() {
+	g := &pg.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		g.paused = true
+		g.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes launches of new tasks halted by Pause.
+func (pg *Group) Resume() {
+	g := &pg.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		g.paused = false
+		close(g.resumed)
+	}
+}
+
+// wait blocks while the gate is closed. It returns immediately if ctx is canceled, since launched tasks will abort soon anyway.
+func (g *launchGate) wait(ctx context.Context) {
+	g.mu.Lock()
+	if !g.paused {
+		g.mu.Unlock()
+		return
+	}
+	resumed := g.resumed
+	g.mu.Unlock()
+
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
+
+func (g *launchGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
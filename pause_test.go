@@ -0,0 +1,49 @@
+package pgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	pg := New()
+
+	var started int32
+	task := func(ctx context.Context) error {
+		atomic.AddInt32(&started, 1)
+		return nil
+	}
+
+	pg.Pause()
+
+	launched := make(chan struct{})
+	go func() {
+		defer close(launched)
+		GoAndForget(pg, task)
+	}()
+
+	if _, ok := TryGo(pg, func(ctx context.Context) (int, error) { return 0, nil }); ok {
+		t.Fatal("TryGo should not launch tasks while paused")
+	}
+
+	select {
+	case <-launched:
+		t.Fatal("launch should be blocked while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if atomic.LoadInt32(&started) != 0 {
+		t.Fatal("no task should be started while paused")
+	}
+
+	pg.Resume()
+	<-launched
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if started != 1 {
+		t.Fatalf("unexpected number of started tasks: %d", started)
+	}
+}
@@ -0,0 +1,66 @@
+package pgroup
+
+import "sync"
+
+// WithPromisePooling makes the Group allocate Promises from pools, and reuse Promises released by Promise.Release for subsequent tasks.
+// It is a performance optimization for hot fan-out loops producing millions of tasks, which reduces the GC pressure of allocating a Promise per task.
+//
+// In this mode, Promises must not be retained after they're released: release each Promise only after Wait has returned and its result has been consumed.
+func WithPromisePooling() Option {
+	return func(pg *Group) {
+		pg.promisePooling = true
+	}
+}
+
+// promisePools holds a pool of Promises for each type of the result.
+var promisePools sync.Map // poolKey[T] -> *sync.Pool
+
+// poolKey is the key of the pool of Promise[T] in promisePools.
+type poolKey[T any] struct{}
+
+func promisePool[T any]() *sync.Pool {
+	if pool, ok := promisePools.Load(poolKey[T]{}); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := promisePools.LoadOrStore(poolKey[T]{}, &sync.Pool{
+		New: func() any { return new(Promise[T]) },
+	})
+	return pool.(*sync.Pool)
+}
+
+// getPooledPromise takes a Promise from the pool, and resets it as a new Promise of the Group.
+func getPooledPromise[T any](pg *Group) *Promise[T] {
+	p := promisePool[T]().Get().(*Promise[T])
+	*p = Promise[T]{
+		done:   make(chan struct{}),
+		pg:     pg,
+		id:     lastPromiseID.Add(1),
+		pooled: true,
+	}
+	return p
+}
+
+// Release returns the Promise to the pool for reuse, if it's allocated by the Group with WithPromisePooling. The Promise must not be used after that.
+//
+// It does nothing for Promises not from the pool, or not resolved yet.
+func (p *Promise[T]) Release() {
+	if !p.pooled {
+		return
+	}
+	select {
+	case <-p.done:
+	default:
+		return
+	}
+	p.putBack()
+}
+
+// putBack returns the Promise from the pool to the pool, whether it's resolved or not. The caller must ensure the Promise is no longer used.
+func (p *Promise[T]) putBack() {
+	if !p.pooled {
+		return
+	}
+	var zero T
+	p.res, p.err, p.pg, p.pooled = zero, nil, nil, false
+	promisePool[T]().Put(p)
+}
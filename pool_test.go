@@ -0,0 +1,48 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithPromisePooling(t *testing.T) {
+	pg := New(WithPromisePooling())
+
+	errExp := errors.New("error!")
+	p1 := Go(pg, func(context.Context) (int, error) { return 1, nil })
+	p2 := Go(pg, func(context.Context) (int, error) { return 0, errExp })
+	if err := pg.Wait(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.Get() != 1 || p2.err != errExp {
+		t.Fatalf("unexpected results: %v, %v", p1.Get(), p2.err)
+	}
+	id1 := p1.ID()
+	p1.Release()
+	p2.Release()
+
+	// released Promises are reset before reuse
+	pg = New(WithPromisePooling())
+	q := Go(pg, func(context.Context) (int, error) { return 2, nil })
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Get() != 2 || q.err != nil || q.ID() == id1 {
+		t.Fatalf("reused Promise should be reset: %v, %v, %v", q.Get(), q.err, q.ID())
+	}
+}
+
+func TestPromise_Release_notPooled(t *testing.T) {
+	pg := New()
+	p := Go(pg, func(context.Context) (int, error) { return 1, nil })
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// no-op for Promises not from the pool
+	p.Release()
+	if p.Get() != 1 || p.pg != pg {
+		t.Fatal("Release should not affect Promises not from the pool")
+	}
+}
@@ -0,0 +1,29 @@
+package pgroup
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	pg := WithContextNamed(context.Background(), "batch", WithPprofLabels())
+
+	labels := func(ctx context.Context) ([2]string, error) {
+		g, _ := pprof.Label(ctx, PprofLabelGroup)
+		task, _ := pprof.Label(ctx, PprofLabelTask)
+		return [2]string{g, task}, nil
+	}
+	named := GoNamed(pg, "fetch", labels)
+	unnamed := Go(pg, labels)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := named.Get(); got != [2]string{"batch", "fetch"} {
+		t.Fatalf("unexpected labels: %v", got)
+	}
+	if got := unnamed.Get(); got != [2]string{"batch", ""} {
+		t.Fatalf("unexpected labels: %v", got)
+	}
+}
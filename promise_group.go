@@ -2,7 +2,12 @@ package pgroup
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Group is a collection of goroutines (or, "tasks") in the same cancellation scope.
@@ -16,79 +21,813 @@ type Group struct {
 	err     error
 	errOnce sync.Once
 
-	ctx    context.Context
-	cancel func()
+	// in first-success-wins mode, whether any task has succeeded before failures.
+	firstSuccessWins bool
+	won              atomic.Bool
+	// grace period after the first success before canceling the rest of tasks. See WithSuccessGrace.
+	successGrace time.Duration
+
+	// whether the Group has been canceled due to a reason inside the Group, rather than the parent context.
+	selfCanceled atomic.Bool
+
+	// launch index of the task whose error is the error of the Group (or the first collected one). -1 if it's not from a task.
+	errIndex     int
+	errIndexOnce sync.Once
+
+	// in collect-all mode, errors of all tasks are collected here instead of err.
+	collectAll bool
+	errs       errorList
+
+	// number of failures of tasks which makes the Group fail (see WithFailureThreshold), and failures tolerated so far.
+	failThreshold int
+	tolerated     errorList
+
+	// the parent context passed to WithContext.
+	parent context.Context
+
+	ctx context.Context
+	// cancel cancels ctx with the cause: the error of the failed task, or nil on normal completion.
+	cancel context.CancelCauseFunc
+
+	// the Group in whose task the Group is created, if any.
+	outer *Group
+
+	// whether RequestStop has been called.
+	stopRequested atomic.Bool
+
+	// name of the Group, joined with names of outer Groups with "/".
+	name string
+
+	// logger for lifecycle of tasks. nil means no logging.
+	logger *slog.Logger
+
+	timings taskTimings
+	running taskRegistry
+
+	// number of tasks launched so far, which is also the launch index of the next task.
+	launched atomic.Int64
+	// number of tasks failed so far.
+	failed atomic.Int64
+	// number of tasks skipped on launch so far. See WithFailFastOnCanceledParent.
+	skipped atomic.Int64
+
+	// whether the parent context had already been canceled when the Group was created.
+	parentCanceled bool
+	failFast       FailFastMode
+
+	// semaphore limiting the number of active tasks. nil means no limit.
+	limiter     limiter
+	fairLimiter bool
+	onSaturated func()
+	// limit set by WithLimit, applied after all options so that it respects WithFairLimiter.
+	initLimit    int
+	hasInitLimit bool
+
+	// per-class semaphores set by SetClassLimit.
+	classMu     sync.RWMutex
+	classLimits map[string]chanLimiter
+
+	// rate limiter of launches. nil means no limit.
+	rate *rateLimiter
+
+	gate launchGate
+
+	panicHandler    func(r any, stack []byte) error
+	isolatePanics   bool
+	cancelErrMapper func(error) error
+
+	// spawner of tasks. nil means launching a new goroutine for each task.
+	spawn   func(run func())
+	workers *workerPool
+	sched   *deterministicScheduler
+
+	// whether tasks run under pprof labels. See WithPprofLabels.
+	pprofLabels bool
+
+	// whether stacks of launch sites of tasks are captured. See WithCaptureLaunchSite.
+	captureSites bool
+
+	// whether Promises are allocated from pools. See WithPromisePooling.
+	promisePooling bool
+
+	// errors of tasks launched by GoSideEffect.
+	sideEffects namedErrorList
+
+	// Promises registered by GoRegistered.
+	registry promiseMap
+	// Promises memoized by GoOnce, and whether they are kept across Reset. See WithPersistentCache.
+	once            promiseMap
+	persistentCache bool
+
+	// callbacks registered by ForEachResult. Each of them is func(T, error) for some T.
+	resultHandlers []any
+
+	// timeout of the Group set by WithTimeout. 0 means no timeout.
+	timeout     time.Duration
+	stopTimeout context.CancelFunc
+	// deadline of contexts of tasks set by WithTaskDeadline. Zero means no deadline.
+	taskDeadline time.Time
+	// timeout of Wait set by WithWaitTimeout. 0 means no timeout.
+	waitTimeout time.Duration
+
+	hooks        lifecycleHooks
+	onFirstError func(error)
+	onNoTasks    func()
+	finishOnce   sync.Once
+	// whether Wait has started finishing the Group, after all tasks have completed.
+	finishing atomic.Bool
+	// whether the Group's context has been canceled just because Wait finished, rather than a failure or the parent context.
+	canceledOnFinish atomic.Bool
+	// whether the Group's context has been canceled by another Group linked by LinkCancel.
+	canceledByLink atomic.Bool
+	// closed when Wait has finished.
+	done chan struct{}
+	// when all tasks have completed, set on the finish of Wait.
+	finishedAt time.Time
+	// the errors to be returned from Wait and WaitIgnoreCancel, fixed on the finish of Wait.
+	finalErr             error
+	finalErrIgnoreCancel error
+
+	// the mutex scoped to the Group. See Lock.
+	mu chan struct{}
+
+	// opaque metadata attached to the Group. See Set and GetMeta.
+	meta sync.Map
 }
 
 // New returns a new Group whose parent context is an empty context.
-func New() *Group {
-	return WithContext(context.Background())
+func New(opts ...Option) *Group {
+	return WithContext(context.Background(), opts...)
 }
 
 // WithContext returns a new Group with the "parent context".
 // When the parent context is canceled, all tasks run in the Group will be canceled.
-func WithContext(ctx context.Context) *Group {
-	ctx, cancel := context.WithCancel(ctx)
-	return &Group{
-		ctx:    ctx,
-		cancel: cancel,
+//
+// The behavior of the Group can be customized by options.
+func WithContext(ctx context.Context, opts ...Option) *Group {
+	pg := &Group{
+		parent:   ctx,
+		errIndex: -1,
+		done:     make(chan struct{}),
+		mu:       make(chan struct{}, 1),
 	}
+	pg.outer, _ = FromContext(ctx)
+	pg.initContext()
+
+	for _, opt := range opts {
+		opt(pg)
+	}
+	if pg.hasInitLimit {
+		pg.SetLimit(pg.initLimit)
+	}
+	if pg.timeout > 0 {
+		pg.ctx, pg.stopTimeout = context.WithTimeout(pg.ctx, pg.timeout)
+	}
+	return pg
+}
+
+// initContext sets up the Group's context derived from the parent context.
+// The timeout set by WithTimeout is applied here only if it's already set, i.e. on Reset.
+func (pg *Group) initContext() {
+	pg.parentCanceled = pg.parent.Err() != nil
+
+	ctx, cancel := context.WithCancelCause(pg.parent)
+	pg.cancel = cancel
+	pg.ctx = context.WithValue(ctx, groupKey{}, pg)
+	if pg.timeout > 0 {
+		pg.ctx, pg.stopTimeout = context.WithTimeout(pg.ctx, pg.timeout)
+	}
+}
+
+// NewNamed returns a new Group with the name, whose parent context is an empty context.
+// See WithContextNamed for details about names of Groups.
+func NewNamed(name string, opts ...Option) *Group {
+	return WithContextNamed(context.Background(), name, opts...)
+}
+
+// WithContextNamed returns a new Group with the "parent context" and the name.
+//
+// The name is prepended to the error returned from Wait, and included in logs.
+// If the Group is created in a task of another named Group (i.e. ctx is derived from a context passed to a task), the name is joined to the name of the outer Group with "/", like "outer/inner".
+func WithContextNamed(ctx context.Context, name string, opts ...Option) *Group {
+	if outer, ok := FromContext(ctx); ok && outer.name != "" {
+		name = outer.name + "/" + name
+	}
+	pg := WithContext(ctx, opts...)
+	pg.name = name
+	return pg
+}
+
+type groupKey struct{}
+
+// FromContext returns the Group which the task that received ctx belongs to.
+// The second return value reports whether ctx is derived from a context passed to a task in a Group.
+func FromContext(ctx context.Context) (*Group, bool) {
+	pg, ok := ctx.Value(groupKey{}).(*Group)
+	return pg, ok
+}
+
+// Name returns the name of the Group, including names of outer Groups. It returns an empty string for Groups without names.
+func (pg *Group) Name() string {
+	return pg.name
 }
 
 // Wait blocks until all tasks have completed or canceled.
 //
 // Promise.Get returns meaningful value only after the call to Wait() returned nil (no error).
+//
+// In collect-all mode (see WithCollectAll), it returns all errors returned from tasks joined into an error.
+// With WithWaitTimeout, it gives up waiting after the timeout.
+//
+// It is safe to call Wait concurrently from multiple goroutines, and multiple times: all of them return the identical error value, which is fixed when the first call finishes; failures reported afterwards (e.g. by Cancel) are ignored.
 func (pg *Group) Wait() error {
+	if err := pg.waitGuarded(true); err != nil {
+		return err
+	}
+	return pg.waitErr()
+}
+
+// waitTasks blocks until all tasks have completed. With the deterministic scheduler, it runs queued tasks on the calling goroutine.
+func (pg *Group) waitTasks() {
+	if pg.sched != nil {
+		pg.sched.drain()
+	}
 	pg.wg.Wait()
-	pg.cancel()
+}
 
-	return pg.err
+// finish cancels the Group's context and runs hooks, only once.
+// Cancel hooks are run synchronously here (if they haven't been triggered yet) so that all of them complete before done hooks.
+func (pg *Group) finish() {
+	pg.finishOnce.Do(func() {
+		pg.finishing.Store(true)
+		pg.finishedAt = time.Now()
+		pg.sealErr()
+		pg.finalErr = pg.waitErrFiltered(nil)
+		pg.finalErrIgnoreCancel = pg.waitErrFiltered(isCanceled)
+		if pg.ctx.Err() == nil {
+			pg.canceledOnFinish.Store(true)
+		}
+		pg.cancel(nil)
+		if pg.stopTimeout != nil {
+			pg.stopTimeout()
+		}
+		if pg.workers != nil {
+			pg.workers.close()
+		}
+		pg.runCancelHooks()
+		if pg.onNoTasks != nil && pg.launched.Load() == 0 {
+			pg.onNoTasks()
+		}
+		pg.runDoneHooks()
+		close(pg.done)
+	})
+}
+
+// sealErr fixes the error of the Group, so that every call of Wait (even concurrent ones) returns the same error: failures reported afterwards (e.g. by Cancel) are ignored.
+// Running errOnce (and errIndexOnce) here also makes reading the error safe against such concurrent reports.
+func (pg *Group) sealErr() {
+	pg.errOnce.Do(func() {})
+	pg.errIndexOnce.Do(func() {})
+	pg.errs.seal()
+	pg.tolerated.seal()
+}
+
+// GroupState is a state in the lifecycle of a Group, reported by State.
+type GroupState int
+
+const (
+	// StateActive is the state where the Group is running tasks (or waiting for tasks to be launched), and hasn't been canceled.
+	StateActive GroupState = iota
+	// StateFailing is the state where the Group has been canceled (due to a failure of a task, Cancel, or the cancellation of the parent context) and its tasks are draining.
+	StateFailing
+	// StateDone is the state where all tasks have completed and Wait has finished (or is finishing) the Group.
+	StateDone
+)
+
+func (s GroupState) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateFailing:
+		return "failing"
+	case StateDone:
+		return "done"
+	}
+	return fmt.Sprintf("GroupState(%d)", int(s))
+}
+
+// State returns the current state of the Group in its lifecycle: StateActive, then StateFailing if the Group is canceled before all tasks complete, and finally StateDone when Wait finishes.
+// States never go back; e.g. a Group once reported StateFailing never reports StateActive again.
+//
+// It is useful for observability endpoints, and for tests asserting on the lifecycle.
+func (pg *Group) State() GroupState {
+	if pg.finishing.Load() {
+		return StateDone
+	}
+	if pg.ctx.Err() != nil {
+		return StateFailing
+	}
+	return StateActive
+}
+
+// Done returns a channel which is closed when Wait has finished, i.e. all tasks have completed and the Group has been canceled.
+// It lets other goroutines observe the completion of the Group, while one goroutine owns Wait.
+//
+// Unlike the Done channel of the Group's context, it isn't closed as soon as the Group is canceled.
+func (pg *Group) Done() <-chan struct{} {
+	return pg.done
+}
+
+// IsDone reports whether Wait has finished, without blocking.
+func (pg *Group) IsDone() bool {
+	select {
+	case <-pg.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitErr returns the error to be returned from Wait.
+// Once the Group has finished, it returns the error fixed by finish, so that every call returns the identical error value.
+func (pg *Group) waitErr() error {
+	if pg.IsDone() {
+		return pg.finalErr
+	}
+	return pg.waitErrFiltered(nil)
+}
+
+// waitErrFiltered returns the error to be returned from Wait, excluding errors for which skip reports true.
+// skip == nil means no error is excluded.
+func (pg *Group) waitErrFiltered(skip func(error) bool) error {
+	var err error
+	if pg.collectAll {
+		err = pg.errs.err(skip)
+	} else if skip == nil || !skip(pg.err) {
+		err = pg.err
+	}
+	if err == nil {
+		return nil
+	}
+	err = pg.wrapDeadlineError(err)
+	if pg.name == "" {
+		return err
+	}
+	return wrapGroupError(pg.name, err)
+}
+
+// WaitIndexed is like Wait, but it also returns the launch index of the task whose error is returned: tasks are indexed from 0 in the order they are launched on the Group.
+// In collect-all mode, the index of the task which failed first is returned.
+// The index is -1 if there is no error, or the error is not from a task (e.g. given to Cancel).
+//
+// It helps correlate failures back to inputs without naming tasks.
+func (pg *Group) WaitIndexed() (int, error) {
+	err := pg.Wait()
+	if err == nil {
+		return -1, nil
+	}
+	return pg.errIndex, err
+}
+
+// WaitIgnoreCancel is like Wait, but it doesn't treat context cancellation (context.Canceled) as a failure.
+// It returns nil if the only error is a context cancellation, but it returns genuine errors from tasks.
+// In collect-all mode, cancellation errors are excluded from the joined error.
+//
+// It is useful for workflows where the cancellation of the parent context is expected (e.g. client disconnect), to distinguish "being told to stop" from "something broke".
+func (pg *Group) WaitIgnoreCancel() error {
+	if err := pg.waitGuarded(true); err != nil {
+		return err
+	}
+	return pg.finalErrIgnoreCancel
+}
+
+// WaitNoCancel is like Wait, but it doesn't cancel the Group's context after all tasks have completed, so that the Group remains usable for launching more tasks and Wait-ing again.
+// Hooks registered by OnDone are not run, and Done is not closed; they are left for the final call to Wait.
+//
+// Note that errors are sticky: once a task has failed, the Group has been canceled and every subsequent call returns the error.
+func (pg *Group) WaitNoCancel() error {
+	if err := pg.waitGuarded(false); err != nil {
+		return err
+	}
+	return pg.waitErr()
+}
+
+// Reset makes the Group which has finished (i.e. Wait has returned) reusable for another round of tasks, as if it were newly created with the same parent context and options.
+// The error, the cancellation, hooks and counters of tasks are cleared; the configuration (options, limits) and metadata (see Set) are kept.
+// Promises registered by GoRegistered and results cached by GoOnce are also cleared, unless WithPersistentCache is specified for the latter.
+//
+// It must not be called concurrently with any other method of the Group. It panics if Wait hasn't finished yet.
+func (pg *Group) Reset() {
+	if !pg.IsDone() {
+		panic("pgroup: Reset before Wait finished")
+	}
+
+	pg.err = nil
+	pg.errOnce = sync.Once{}
+	pg.errIndex = -1
+	pg.errIndexOnce = sync.Once{}
+	pg.errs.reset()
+	pg.tolerated.reset()
+	pg.won.Store(false)
+	pg.selfCanceled.Store(false)
+	pg.stopRequested.Store(false)
+
+	pg.timings.reset()
+	pg.launched.Store(0)
+	pg.failed.Store(0)
+	pg.skipped.Store(0)
+
+	pg.hooks.reset()
+	pg.finishOnce = sync.Once{}
+	pg.finishing.Store(false)
+	pg.canceledOnFinish.Store(false)
+	pg.canceledByLink.Store(false)
+	pg.finishedAt = time.Time{}
+	pg.finalErr = nil
+	pg.finalErrIgnoreCancel = nil
+	pg.done = make(chan struct{})
+
+	pg.initContext()
+	if pg.workers != nil {
+		pg.workers.reopen()
+	}
+	pg.sideEffects.reset()
+	pg.registry.clear()
+	if !pg.persistentCache {
+		pg.once.clear()
+	}
+}
+
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+// Set attaches an arbitrary metadata to the Group with the key.
+// It is intended to associate some state with a Group instance for middleware, and independent of the context values which tasks see.
+func (pg *Group) Set(key, val any) {
+	pg.meta.Store(key, val)
+}
+
+// GetMeta returns the metadata attached to the Group with the key by Set.
+// The second return value reports whether the metadata is present.
+func (pg *Group) GetMeta(key any) (any, bool) {
+	return pg.meta.Load(key)
 }
 
 // Go launches the given function in a new goroutine to get some result.
 // Result of the function will be available via the Promise returned after the call to Group's Wait() returned nil (no error).
+//
+// It panics if f is nil.
 func Go[T any](pg *Group, f func(ctx context.Context) (T, error)) *Promise[T] {
-	pg.wg.Add(1)
-	p := &Promise[T]{}
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	p := newPromise[T](pg)
 
-	run := func() {
-		defer pg.wg.Done()
+	task, settle := promiseTask(p, f)
+	pg.launch(nil, task, settle)
+
+	return p
+}
 
-		res, err := f(pg.ctx)
+// promiseTask returns a task which runs f, and the function to settle p with the result of the task.
+func promiseTask[T any](p *Promise[T], f func(ctx context.Context) (T, error)) (task func(ctx context.Context) error, settle func(err error)) {
+	var res T
+	task = func(ctx context.Context) error {
+		var err error
+		res, err = f(ctx)
+		return err
+	}
+	settle = func(err error) {
 		if err != nil {
-			pg.errOnce.Do(func() {
-				pg.err = err
-				pg.cancel()
-			})
-			return
+			var zero T
+			res = zero
+		}
+		p.resolve(res, err)
+		notifyResult(p.pg, res, err)
+		if err == nil && p.pg.firstSuccessWins {
+			p.pg.win()
 		}
-		p.res = res
 	}
-	go run()
+	return task, settle
+}
 
-	return p
+// ForEachResult registers f as a callback which is invoked with the result of each task launched by Go (and other functions which return Promise[T]) upon its completion.
+// f is called only for tasks whose result type is T.
+//
+// It must be called before launching tasks. f is invoked from goroutines of the tasks, so it must be safe for concurrent use.
+func ForEachResult[T any](pg *Group, f func(T, error)) {
+	pg.resultHandlers = append(pg.resultHandlers, f)
+}
+
+func notifyResult[T any](pg *Group, res T, err error) {
+	for _, h := range pg.resultHandlers {
+		if f, ok := h.(func(T, error)); ok {
+			f(res, err)
+		}
+	}
 }
 
 // GoAndForget launches the given function in a new goroutine to perform some side-effects.
+//
+// It panics if f is nil.
 func GoAndForget(pg *Group, f func(ctx context.Context) error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	pg.launch(nil, f, nil)
+}
+
+// GoAndForgetP is like GoAndForget, but it returns a Promise of the task, whose error is the one returned from f.
+// The value of the Promise is meaningless, while its error and completion are useful to inspect outcomes of individual side-effect tasks, e.g. in collect-all mode.
+//
+// It panics if f is nil.
+func GoAndForgetP(pg *Group, f func(ctx context.Context) error) *Promise[struct{}] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	return Go(pg, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+}
+
+// GoTo launches the given function in a new goroutine to perform some side-effects, like GoAndForget.
+// In addition, the error returned from the function (nil on success) is sent to errCh as soon as the task completes, so that the caller can handle per-task errors reactively without holding Promises.
+//
+// Sends to errCh block, so the caller should keep receiving from errCh (or make it buffered enough) until all tasks have completed.
+// The Group never closes errCh; it is the caller's responsibility.
+// It panics if f is nil.
+func GoTo(pg *Group, errCh chan<- error, f func(ctx context.Context) error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	pg.launch(nil, f, func(err error) { errCh <- err })
+}
+
+// ThenGroup launches the given function in a new goroutine after the task corresponding to p has completed successfully, passing its result to the function.
+// The Group which p belongs to is also passed to the function, so it can launch additional tasks depending on the result. These tasks join the same Group as the other tasks.
+//
+// If the task corresponding to p failed, f is not called and the returned Promise holds the same error as p.
+// It panics if f is nil, or p doesn't belong to any Group (i.e. it's created by NewPromise).
+func ThenGroup[T, U any](p *Promise[T], f func(ctx context.Context, pg *Group, v T) (U, error)) *Promise[U] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	if p.pg == nil {
+		panic(noGroupMsg)
+	}
+	pg := p.pg
+	q := newPromise[U](pg)
+
+	task, settle := promiseTask(q, func(ctx context.Context) (U, error) {
+		<-p.done
+		if p.err != nil {
+			// the error has already been reported to the Group by the task of p.
+			var zero U
+			return zero, propagate(p.err)
+		}
+		return f(ctx, pg, p.res)
+	})
+	pg.launch(nil, task, settle)
+
+	return q
+}
+
+// nilTaskMsg is the panic message on an attempt to launch a nil task function.
+// Tasks are checked at submission time so that the panic occurs at the call site rather than inside the spawned goroutine.
+const nilTaskMsg = "pgroup: nil task function"
+
+// noGroupMsg is the panic message on an attempt to chain a task to a Promise which doesn't belong to any Group.
+const noGroupMsg = "pgroup: promise doesn't belong to any Group"
+
+// launch registers a task to the Group and runs it in a new goroutine.
+// If the task returns non-nil error, the Group records it as its error and cancels all other tasks.
+// If the Group is paused or the concurrency limit is set, it blocks until the task can be started.
+//
+// info is the identity of the task, which may be nil for anonymous tasks.
+// settle (if it's non-nil) is called exactly once with the final error of the task (nil on success), before the error is reported to the Group. It should settle the result of the task.
+// If the task is not going to be run at all, settle is called with the reason.
+func (pg *Group) launch(info *taskInfo, task func(ctx context.Context) error, settle func(err error)) {
+	if pg.skipLaunch(settle) {
+		return
+	}
+	info = pg.captureLaunchSite(info)
+	pg.gate.wait(pg.ctx)
+	pg.rate.wait(pg.ctx)
+	pg.acquire(info)
+	pg.start(info, task, settle)
+}
+
+// skipLaunch reports whether launching a task should be skipped, settling the task if so.
+func (pg *Group) skipLaunch(settle func(err error)) bool {
+	if !pg.parentCanceled {
+		return false
+	}
+	switch pg.failFast {
+	case FailFastSkip:
+		pg.skipped.Add(1)
+		err := pg.ctx.Err()
+		if settle != nil {
+			settle(err)
+		}
+		pg.fail(err)
+		return true
+	case FailFastPanic:
+		panic(canceledParentMsg)
+	}
+	return false
+}
+
+// start runs the task in a new goroutine (or by the spawner configured). The caller must acquire a token from the limiter beforehand.
+func (pg *Group) start(info *taskInfo, task func(ctx context.Context) error, settle func(err error)) {
 	pg.wg.Add(1)
+	idx := int(pg.launched.Add(1) - 1)
+
+	ctx := pg.ctx
+	if info != nil {
+		ctx = context.WithValue(ctx, taskInfoKey{}, info)
+	}
+	stopDeadline := func() {}
+	if !pg.taskDeadline.IsZero() {
+		ctx, stopDeadline = context.WithDeadline(ctx, pg.taskDeadline)
+	}
 
 	run := func() {
 		defer pg.wg.Done()
+		defer stopDeadline()
+		defer pg.release()
+		defer pg.releaseClass(info)
 
-		if err := f(pg.ctx); err != nil {
-			pg.errOnce.Do(func() {
-				pg.err = err
-				pg.cancel()
-			})
+		if info != nil && info.name != "" {
+			id := pg.running.add(info.name)
+			defer pg.running.remove(id)
+		}
+
+		pg.logTaskStart(info)
+		start := time.Now()
+		err := pg.runTaskLabeled(ctx, info, task)
+		dur := time.Since(start)
+
+		// errors propagated from other tasks only settle the result of the task, and are not reported to the Group.
+		propagated, isPropagated := err.(*propagatedError)
+		isolated, isIsolated := err.(*isolatedError)
+		if isPropagated {
+			err = propagated.err
+		} else if isIsolated {
+			err = isolated.err
+		} else {
+			err = pg.mapCancelErr(err)
+			if err != nil && info != nil && info.site != nil {
+				err = &LaunchSiteError{err: err, site: info.site}
+			}
+		}
+		if settle != nil {
+			settle(err)
+		}
+
+		pg.timings.record(dur)
+		if isPropagated {
+			pg.logTaskEnd(info, dur, nil)
+			return
+		}
+		pg.logTaskEnd(info, dur, err)
+		if isIsolated {
+			// isolated errors fail only the task itself.
+			return
+		}
+
+		if err != nil {
+			pg.failed.Add(1)
+			pg.failAt(idx, err)
 		}
 	}
-	go run()
+	pg.spawnTask(run)
+}
+
+// fail records err as the error of the Group if it's the first one, and cancels all tasks.
+// In collect-all mode, it just adds err to the collected errors.
+func (pg *Group) fail(err error) {
+	pg.failAt(-1, err)
+}
+
+// failAt is like fail, but it also records the launch index of the task which returned err. idx is -1 if err is not from a task.
+func (pg *Group) failAt(idx int, err error) {
+	if pg.won.Load() {
+		// the Group has already completed successfully.
+		return
+	}
+	// the hook of the first error is called outside of the Onces, since it may report errors to the Group again (e.g. by Cancel).
+	first := false
+	if pg.collectAll {
+		pg.errs.add(err)
+		pg.errIndexOnce.Do(func() {
+			pg.errIndex = idx
+			first = true
+		})
+		if first {
+			pg.notifyFirstError(err)
+		}
+		return
+	}
+	if pg.failThreshold > 1 && idx >= 0 && pg.ctx.Err() == nil {
+		// tolerate failures of tasks until the threshold is reached.
+		if pg.tolerated.add(err) < pg.failThreshold {
+			return
+		}
+		err = pg.tolerated.err(nil)
+	}
+	pg.errOnce.Do(func() {
+		pg.err = err
+		pg.errIndex = idx
+		pg.cancelSelf(err)
+		first = true
+	})
+	if first {
+		pg.notifyFirstError(err)
+	}
+}
+
+// cancelSelf cancels the Group's context with the cause, due to a reason inside the Group (a failure of a task, or explicit cancellation).
+func (pg *Group) cancelSelf(cause error) {
+	if pg.ctx.Err() == nil {
+		pg.selfCanceled.Store(true)
+	}
+	pg.cancel(cause)
 }
 
 // Promise is a place for the result of a task that will be available at some point.
 type Promise[T any] struct {
-	res T
+	res  T
+	err  error
+	done chan struct{}
+
+	// mu guards the resolution against Split. splits are children returned from Split, resolved along with the Promise.
+	mu     sync.Mutex
+	splits []*Promise[T]
+
+	// the Group which the task corresponding to the Promise belongs to.
+	pg *Group
+
+	id uint64
+
+	// deadline of the task. Zero if not set.
+	deadline time.Time
+
+	// whether the Promise is taken from the pool and not released yet (see WithPromisePooling).
+	pooled bool
+}
+
+// lastPromiseID is the last ID assigned to a Promise.
+var lastPromiseID atomic.Uint64
+
+// NewPromise returns a new Promise which is not tied to any task, along with the function to resolve it.
+// It is useful to bridge results from async sources other than Groups to the Promise ecosystem.
+//
+// Calling resolve sets the result of the Promise and notifies it to dependents. Only the first call of resolve takes effect, and subsequent calls are ignored.
+//
+// The Promise doesn't belong to any Group, so it can't be passed to ThenGroup.
+func NewPromise[T any]() (p *Promise[T], resolve func(T, error)) {
+	p = newPromise[T](nil)
+
+	var once sync.Once
+	resolve = func(res T, err error) {
+		once.Do(func() { p.resolve(res, err) })
+	}
+	return p, resolve
+}
+
+func newPromise[T any](pg *Group) *Promise[T] {
+	if pg != nil && pg.promisePooling {
+		return getPooledPromise[T](pg)
+	}
+	return &Promise[T]{
+		done: make(chan struct{}),
+		pg:   pg,
+		id:   lastPromiseID.Add(1),
+	}
+}
+
+// ID returns the identifier of the underlying result of the Promise, which is unique in the process.
+// Promises returned from Split have the same ID as the original Promise, since they refer to the same result.
+//
+// It is useful to deduplicate dependency edges in DAG builders.
+func (p *Promise[T]) ID() uint64 {
+	return p.id
+}
+
+// resolve sets the result of the task and notifies it to dependents.
+func (p *Promise[T]) resolve(res T, err error) {
+	p.mu.Lock()
+	p.res = res
+	p.err = err
+	close(p.done)
+	splits := p.splits
+	p.splits = nil
+	p.mu.Unlock()
+
+	for _, c := range splits {
+		c.resolve(res, err)
+	}
 }
 
 // Get returns the result of the corresponding task.
@@ -97,3 +836,102 @@ type Promise[T any] struct {
 func (p *Promise[T]) Get() T {
 	return p.res
 }
+
+// await blocks until the Promise resolves or ctx is canceled, and returns the result.
+func (p *Promise[T]) await(ctx context.Context) (T, error) {
+	select {
+	case <-p.done:
+		return p.res, p.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// succeeded returns the result value if the Promise has been resolved successfully.
+func (p *Promise[T]) succeeded() (T, bool) {
+	select {
+	case <-p.done:
+		if p.err == nil {
+			return p.res, true
+		}
+	default:
+	}
+	var zero T
+	return zero, false
+}
+
+// Split returns n Promises which resolve to the same result (value and error) as p when p resolves.
+// It is useful to share a result between several downstream chains without recomputing it.
+//
+// The children resolve at the same time as p, in the same goroutine: if p has already resolved when Split is called, the children are returned already resolved; otherwise they resolve right after p does.
+// In either case, if p belongs to a Group, all the children have resolved before the Group's Wait returns, and Get on any of them yields the same value as p.
+func (p *Promise[T]) Split(n int) []*Promise[T] {
+	ps := make([]*Promise[T], n)
+	for i := range ps {
+		c := newPromise[T](p.pg)
+		c.id = p.id
+		ps[i] = c
+	}
+
+	p.mu.Lock()
+	resolved := false
+	select {
+	case <-p.done:
+		resolved = true
+	default:
+		p.splits = append(p.splits, ps...)
+	}
+	p.mu.Unlock()
+
+	if resolved {
+		for _, c := range ps {
+			c.resolve(p.res, p.err)
+		}
+	}
+	return ps
+}
+
+// Result is a pair of the result value and the error of a task.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Chan returns a channel which receives exactly one Result when the task corresponding to p resolves, and then is closed.
+// It is an adapter for code which expects a channel of the result.
+//
+// Each call starts a new goroutine forwarding the result, and returns a distinct channel.
+func (p *Promise[T]) Chan() <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	go func() {
+		<-p.done
+		ch <- Result[T]{Value: p.res, Err: p.err}
+		close(ch)
+	}()
+	return ch
+}
+
+// PromisesChan returns a channel which receives the Result of each of ps as it resolves (in the order of resolution), and is closed after all of ps have resolved.
+// It bridges a set of Promises to channel-based select loops.
+//
+// The channel is buffered for all of ps, so forwarding goroutines never leak even if the receiver stops receiving early.
+func PromisesChan[T any](ps []*Promise[T]) <-chan Result[T] {
+	ch := make(chan Result[T], len(ps))
+
+	var wg sync.WaitGroup
+	wg.Add(len(ps))
+	for _, p := range ps {
+		p := p
+		go func() {
+			defer wg.Done()
+			<-p.done
+			ch <- Result[T]{Value: p.res, Err: p.err}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	return ch
+}
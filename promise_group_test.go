@@ -3,6 +3,7 @@ package pgroup
 import (
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -78,11 +79,63 @@ func TestGoAndForget_timeoutParentCtx(t *testing.T) {
 	if err == nil {
 		t.Fatal("error is expected")
 	}
-	if err != context.DeadlineExceeded {
+	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
+func TestGoAndForgetP(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	errExp := errors.New("error!")
+	p1 := GoAndForgetP(pg, delayedTask(10*time.Millisecond, func() error { return nil }))
+	p2 := GoAndForgetP(pg, delayedTask(10*time.Millisecond, func() error { return errExp }))
+
+	if err := pg.Wait(); !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.err != nil {
+		t.Fatalf("unexpected error of succeeded task: %v", p1.err)
+	}
+	if p2.err != errExp {
+		t.Fatalf("unexpected error of failed task: %v", p2.err)
+	}
+}
+
+func TestGoTo(t *testing.T) {
+	errExp := errors.New("error!")
+	task := delayedTask(100*time.Millisecond, func() error { return nil })
+	etask := delayedTask(200*time.Millisecond, func() error { return errExp })
+
+	pg := New()
+	errCh := make(chan error, 3)
+
+	GoTo(pg, errCh, task)
+	GoTo(pg, errCh, task)
+	GoTo(pg, errCh, etask)
+
+	err := pg.Wait()
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(errCh)
+
+	var nils, errs int
+	for err := range errCh {
+		if err == nil {
+			nils++
+			continue
+		}
+		if err != errExp {
+			t.Fatalf("unexpected error from channel: %v", err)
+		}
+		errs++
+	}
+	if nils != 2 || errs != 1 {
+		t.Fatalf("unexpected number of results (nils: %d, errors: %d)", nils, errs)
+	}
+}
+
 func TestGo(t *testing.T) {
 	intTask := delayedResultTask(time.Second, func() (int, error) { return 42, nil })
 	strTask := delayedResultTask(time.Second, func() (string, error) { return "result", nil })
@@ -146,30 +199,499 @@ func TestGo_timeoutParentCtx(t *testing.T) {
 	if err == nil {
 		t.Fatal("error is expected")
 	}
-	if err != context.DeadlineExceeded {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestThenGroup(t *testing.T) {
+	nTask := delayedResultTask(100*time.Millisecond, func() (int, error) { return 3, nil })
+
+	pg := New()
+
+	nPromise := Go(pg, nTask)
+	cntPromise := ThenGroup(nPromise, func(ctx context.Context, pg *Group, n int) (int, error) {
+		ps := make([]*Promise[int], 0, n)
+		for i := 1; i <= n; i++ {
+			i := i
+			ps = append(ps, Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return i, nil })))
+		}
+		// results of tasks launched in the callback are not available until the Group's Wait() returned.
+		return len(ps), nil
+	})
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cntPromise.Get() != 3 {
+		t.Fatalf("unexpected result (want: %v, got: %v)", 3, cntPromise.Get())
+	}
+}
+
+func TestThenGroup_err(t *testing.T) {
+	errExp := errors.New("error!")
+	etask := delayedResultTask(100*time.Millisecond, func() (int, error) { return 0, errExp })
+
+	pg := New()
+
+	called := false
+	p := ThenGroup(Go(pg, etask), func(ctx context.Context, pg *Group, n int) (int, error) {
+		called = true
+		return n, nil
+	})
+
+	err := pg.Wait()
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Fatal("callback should not be called if the preceding task failed")
+	}
+	if p.err != errExp {
+		t.Fatalf("unexpected error of dependent promise: %v", p.err)
+	}
+}
+
+func TestGo_nilTask(t *testing.T) {
+	pg := New()
+
+	assertPanics(t, nilTaskMsg, func() { Go[int](pg, nil) })
+	assertPanics(t, nilTaskMsg, func() { GoAndForget(pg, nil) })
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPromise_Split(t *testing.T) {
+	pg := New()
+
+	p := Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 42, nil }))
+	ps := p.Split(3)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ps) != 3 {
+		t.Fatalf("unexpected number of promises: %d", len(ps))
+	}
+	for _, c := range ps {
+		<-c.done
+		if c.Get() != 42 || c.err != nil {
+			t.Fatalf("unexpected result of split promise: %v, %v", c.Get(), c.err)
+		}
+	}
+}
+
+func TestPromise_Split_ordering(t *testing.T) {
+	resolved := func(c *Promise[int]) bool {
+		select {
+		case <-c.done:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// split after the source has resolved: children are returned already resolved
+	pg := New()
+	p := Go(pg, func(context.Context) (int, error) { return 1, nil })
+	<-p.done
+	for _, c := range p.Split(2) {
+		if !resolved(c) || c.Get() != 1 {
+			t.Fatal("children of a resolved Promise should be resolved")
+		}
+	}
+	pg.Wait()
+
+	// split concurrently with the resolution of the source: children have resolved when Wait returns
+	for i := 0; i < 100; i++ {
+		pg := New()
+		start := make(chan struct{})
+		p := Go(pg, func(context.Context) (int, error) { <-start; return i, nil })
+
+		go close(start)
+		ps := p.Split(3)
+		if err := pg.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, c := range ps {
+			if !resolved(c) || c.Get() != i {
+				t.Fatalf("children should be resolved before Wait returns: %v", c.Get())
+			}
+		}
+	}
+
+	// same for Promises not belonging to any Group
+	for i := 0; i < 100; i++ {
+		p, resolve := NewPromise[int]()
+		go resolve(i, nil)
+		for _, c := range p.Split(2) {
+			<-c.done
+			if c.Get() != i {
+				t.Fatalf("unexpected result of split promise: %v", c.Get())
+			}
+		}
+	}
+}
+
+func TestGroup_meta(t *testing.T) {
+	type key struct{}
+
+	pg := New()
+
+	if _, ok := pg.GetMeta(key{}); ok {
+		t.Fatal("metadata should not be present")
+	}
+
+	pg.Set(key{}, "value")
+	v, ok := pg.GetMeta(key{})
+	if !ok || v != "value" {
+		t.Fatalf("unexpected metadata: %v, %v", v, ok)
+	}
+}
+
+func TestNewPromise(t *testing.T) {
+	p, resolve := NewPromise[int]()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		resolve(42, nil)
+	}()
+	<-p.done
+
+	// subsequent resolutions are ignored.
+	resolve(0, errors.New("ignored"))
+
+	if p.Get() != 42 || p.err != nil {
+		t.Fatalf("unexpected result: %v, %v", p.Get(), p.err)
+	}
+
+	assertPanics(t, noGroupMsg, func() {
+		ThenGroup(p, func(ctx context.Context, pg *Group, v int) (int, error) { return v, nil })
+	})
+}
+
+func TestNamedGroup(t *testing.T) {
+	errExp := errors.New("task failed")
+
+	outer := NewNamed("outer")
+	GoAndForget(outer, func(ctx context.Context) error {
+		pg, ok := FromContext(ctx)
+		if !ok || pg != outer {
+			t.Error("task context should carry the Group")
+		}
+
+		inner := WithContextNamed(ctx, "inner")
+		if inner.Name() != "outer/inner" {
+			t.Errorf("unexpected name of inner group: %q", inner.Name())
+		}
+		GoAndForget(inner, func(ctx context.Context) error { return errExp })
+		return inner.Wait()
+	})
+
+	err := outer.Wait()
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err.Error() != "outer/inner: task failed" {
+		t.Fatalf("unexpected error message: %q", err.Error())
+	}
+}
+
+func TestForEachResult(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New(WithCollectAll())
+
+	var mu sync.Mutex
+	var sum, errs int
+	ForEachResult(pg, func(v int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs++
+			return
+		}
+		sum += v
+	})
+
+	for i := 1; i <= 3; i++ {
+		i := i
+		Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return i, nil }))
+	}
+	Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 0, errExp }))
+	// results of other types are not passed to the callback.
+	Go(pg, delayedResultTask(100*time.Millisecond, func() (string, error) { return "", nil }))
+
+	if err := pg.Wait(); !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum != 6 || errs != 1 {
+		t.Fatalf("unexpected results passed to callback (sum: %v, errs: %v)", sum, errs)
+	}
+}
+
+func TestPromise_Chan(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New(WithCollectAll())
+	p1 := Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 42, nil }))
+	p2 := Go(pg, delayedResultTask(100*time.Millisecond, func() (int, error) { return 0, errExp }))
+
+	r1 := <-p1.Chan()
+	if r1.Value != 42 || r1.Err != nil {
+		t.Fatalf("unexpected result: %+v", r1)
+	}
+
+	ch := p2.Chan()
+	if r2 := <-ch; r2.Err != errExp {
+		t.Fatalf("unexpected result: %+v", r2)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("channel should be closed after the result")
+	}
+
+	_ = pg.Wait()
+}
+
+func TestPromisesChan(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New(WithCollectAll())
+	ps := []*Promise[int]{
+		Go(pg, delayedResultTask(60*time.Millisecond, func() (int, error) { return 1, nil })),
+		Go(pg, delayedResultTask(10*time.Millisecond, func() (int, error) { return 0, errExp })),
+		Go(pg, delayedResultTask(30*time.Millisecond, func() (int, error) { return 3, nil })),
+	}
+
+	var got []Result[int]
+	for r := range PromisesChan(ps) {
+		got = append(got, r)
+	}
+	want := []Result[int]{{Err: errExp}, {Value: 3}, {Value: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected results: %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("results should be emitted in the order of resolution: %+v", got)
+		}
+	}
+
+	_ = pg.Wait()
+}
+
+func TestWaitIgnoreCancel(t *testing.T) {
+	task := delayedTask(time.Second, func() error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pg := WithContext(ctx)
+	GoAndForget(pg, task)
+	GoAndForget(pg, task)
+	cancel()
+
+	if err := pg.WaitIgnoreCancel(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// genuine errors are still returned.
+	errExp := errors.New("error!")
+	pg = New(WithCollectAll())
+	GoAndForget(pg, func(ctx context.Context) error { return context.Canceled })
+	GoAndForget(pg, func(ctx context.Context) error { return errExp })
+
+	err := pg.WaitIgnoreCancel()
+	if !errors.Is(err, errExp) || errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGroup_Done(t *testing.T) {
+	pg := New()
+	GoAndForget(pg, delayedTask(20*time.Millisecond, func() error { return nil }))
+
+	if pg.IsDone() {
+		t.Fatal("Group should not be done before Wait")
+	}
+	pg.Cancel(nil)
+	if pg.IsDone() {
+		t.Fatal("Group should not be done just by the cancellation")
+	}
+
+	go pg.Wait()
+	select {
+	case <-pg.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done should be closed after Wait")
+	}
+	if !pg.IsDone() {
+		t.Fatal("Group should be done after Wait")
+	}
+}
+
+func TestGroup_State(t *testing.T) {
+	pg := New()
+	if s := pg.State(); s != StateActive {
+		t.Fatalf("unexpected state: %v", s)
+	}
+
+	release := make(chan struct{})
+	GoAndForget(pg, func(context.Context) error { <-release; return nil })
+	GoAndForget(pg, func(context.Context) error { return errors.New("error!") })
+
+	// wait for the failure
+	<-pg.ctx.Done()
+	if s := pg.State(); s != StateFailing {
+		t.Fatalf("unexpected state: %v", s)
+	}
+
+	close(release)
+	pg.Wait()
+	if s := pg.State(); s != StateDone {
+		t.Fatalf("unexpected state: %v", s)
+	}
+}
+
+func TestWaitNoCancel(t *testing.T) {
+	pg := New()
+
+	for round := 0; round < 3; round++ {
+		p := Go(pg, delayedResultTask(time.Millisecond, func() (int, error) { return round, nil }))
+		if err := pg.WaitNoCancel(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Get() != round {
+			t.Fatalf("unexpected result: %v", p.Get())
+		}
+		if pg.ctx.Err() != nil {
+			t.Fatal("Group should not be canceled by WaitNoCancel")
+		}
+	}
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pg.ctx.Err() == nil {
+		t.Fatal("Group should be canceled by Wait")
+	}
+}
+
+func TestPromise_ID(t *testing.T) {
+	pg := New()
+
+	p1 := Go(pg, func(ctx context.Context) (int, error) { return 1, nil })
+	p2 := Go(pg, func(ctx context.Context) (int, error) { return 2, nil })
+	ps := p1.Split(2)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p1.ID() == p2.ID() {
+		t.Fatal("distinct promises should have distinct IDs")
+	}
+	for _, c := range ps {
+		if c.ID() != p1.ID() {
+			t.Fatalf("split promises should have the same ID as the source (want: %v, got: %v)", p1.ID(), c.ID())
+		}
+	}
+}
+
+func TestWaitIndexed(t *testing.T) {
+	errExp := errors.New("error!")
+
+	pg := New()
+	for i := 0; i < 5; i++ {
+		i := i
+		GoAndForget(pg, delayedTask(time.Duration(i)*10*time.Millisecond, func() error {
+			if i == 2 {
+				return errExp
+			}
+			return nil
+		}))
+	}
+
+	idx, err := pg.WaitIndexed()
+	if err != errExp {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if idx != 2 {
+		t.Fatalf("unexpected index of failed task (want: %v, got: %v)", 2, idx)
+	}
+
+	pg = New()
+	GoAndForget(pg, func(ctx context.Context) error { return nil })
+	if idx, err := pg.WaitIndexed(); idx != -1 || err != nil {
+		t.Fatalf("unexpected result: %v, %v", idx, err)
+	}
 }
 
 func delayedTask(delay time.Duration, f func() error) func(context.Context) error {
 	return func(ctx context.Context) error {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
-			return f()
+		if err := Sleep(ctx, delay); err != nil {
+			return err
 		}
+		return f()
 	}
 }
 
 func delayedResultTask[T any](delay time.Duration, f func() (T, error)) func(context.Context) (T, error) {
 	return func(ctx context.Context) (T, error) {
-		select {
-		case <-ctx.Done():
+		if err := Sleep(ctx, delay); err != nil {
 			var zero T
-			return zero, ctx.Err()
-		case <-time.After(delay):
-			return f()
+			return zero, err
+		}
+		return f()
+	}
+}
+
+func assertPanics(t *testing.T, want any, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("panic is expected")
+		}
+		if r != want {
+			t.Fatalf("unexpected panic (want: %v, got: %v)", want, r)
+		}
+	}()
+	f()
+}
+
+func TestWait_concurrent(t *testing.T) {
+	for _, collectAll := range []bool{false, true} {
+		opts := []Option{}
+		if collectAll {
+			opts = append(opts, WithCollectAll())
+		}
+		// errors of named Groups are wrapped, which must not make errors of Waits distinct.
+		pg := WithContextNamed(context.Background(), "g", opts...)
+
+		errExp := errors.New("error!")
+		GoAndForget(pg, delayedTask(20*time.Millisecond, func() error { return errExp }))
+
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		for i := range errs {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = pg.Wait()
+			}()
+		}
+		// failures reported during or after Wait don't change its error.
+		go pg.Cancel(errors.New("late"))
+		wg.Wait()
+
+		for _, err := range errs {
+			if err == nil || err != errs[0] {
+				t.Fatalf("concurrent Waits should return the identical error (collectAll: %v): %v", collectAll, errs)
+			}
+		}
+		if err := pg.Wait(); err != errs[0] {
+			t.Fatalf("error should be fixed after Wait (collectAll: %v): %v", collectAll, err)
 		}
 	}
 }
@@ -0,0 +1,72 @@
+package pgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the rate of launching tasks, based on GCRA (generic cell rate algorithm).
+// A nil *rateLimiter means no limit.
+type rateLimiter struct {
+	mu sync.Mutex
+	// interval between launches in the steady state.
+	interval time.Duration
+	// how much launches may go ahead of the steady state, which allows bursts.
+	tolerance time.Duration
+	// theoretical arrival time of the next launch.
+	tat time.Time
+}
+
+func newRateLimiter(n int, per time.Duration) *rateLimiter {
+	interval := per / time.Duration(n)
+	return &rateLimiter{
+		interval:  interval,
+		tolerance: per - interval,
+	}
+}
+
+// reserve returns how long the launch at now has to wait. The reservation is committed if commit is true or the launch doesn't have to wait.
+func (r *rateLimiter) reserve(now time.Time, commit bool) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tat := r.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	delay := tat.Sub(now) - r.tolerance
+	if delay < 0 {
+		delay = 0
+	}
+	if commit || delay == 0 {
+		r.tat = tat.Add(r.interval)
+	}
+	return delay
+}
+
+// wait blocks until a launch is allowed, or ctx is done.
+func (r *rateLimiter) wait(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	delay := r.reserve(time.Now(), true)
+	if delay == 0 {
+		return
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// tryTake reports whether a launch is allowed right now, reserving it if so.
+func (r *rateLimiter) tryTake() bool {
+	if r == nil {
+		return true
+	}
+	return r.reserve(time.Now(), false) == 0
+}
@@ -0,0 +1,39 @@
+package pgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	// burst of 2, then 1 task per 25ms
+	pg := New(WithRateLimit(2, 50*time.Millisecond))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		GoAndForget(pg, func(context.Context) error { return nil })
+	}
+	elapsed := time.Since(start)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if elapsed < 40*time.Millisecond {
+		t.Fatalf("launches should be rate limited: %v", elapsed)
+	}
+}
+
+func TestTryGo_rateLimited(t *testing.T) {
+	pg := New(WithRateLimit(1, time.Hour))
+
+	if _, ok := TryGo(pg, delayedResultTask(0, func() (int, error) { return 1, nil })); !ok {
+		t.Fatal("first task should be launched")
+	}
+	if _, ok := TryGo(pg, delayedResultTask(0, func() (int, error) { return 2, nil })); ok {
+		t.Fatal("second task should not be launched")
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
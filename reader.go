@@ -0,0 +1,65 @@
+package pgroup
+
+import "context"
+
+// GroupReader is a read-only view of a Group, returned from Group.Reader.
+// It exposes only the observation of the Group, so that subsystems (e.g. observers or middleware) given it can't launch tasks nor cancel the Group.
+type GroupReader interface {
+	// Context returns a context which is canceled along with the Group's context, and carries the same values except the Group itself (FromContext reports false for it).
+	Context() context.Context
+	// Err returns the error of the Group so far: the error Wait returns if Wait has finished, or the cause of the cancellation if the Group has been canceled. It returns nil while the Group is active without failures.
+	// In collect-all mode (see WithCollectAll), it returns errors collected so far.
+	Err() error
+	// Stats returns a snapshot of counters of tasks (see Group.Stats).
+	Stats() Stats
+	// Done returns a channel which is closed when Wait has finished (see Group.Done).
+	Done() <-chan struct{}
+}
+
+// Reader returns a read-only view of the Group, for passing to subsystems with the least privilege.
+func (pg *Group) Reader() GroupReader {
+	return groupReader{pg: pg}
+}
+
+// groupReader is the implementation of GroupReader. The Group is hidden in an unexported field, so that the holder can't get to it via type assertions.
+type groupReader struct {
+	pg *Group
+}
+
+func (r groupReader) Context() context.Context {
+	return hiddenGroupContext{r.pg.ctx}
+}
+
+// hiddenGroupContext hides the Group which the context belongs to, so that FromContext can't get to it.
+type hiddenGroupContext struct {
+	context.Context
+}
+
+func (c hiddenGroupContext) Value(key any) any {
+	if _, ok := key.(groupKey); ok {
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
+func (r groupReader) Err() error {
+	pg := r.pg
+	if pg.IsDone() {
+		return pg.waitErr()
+	}
+	if pg.collectAll {
+		return pg.errs.err(nil)
+	}
+	if pg.ctx.Err() != nil {
+		return context.Cause(pg.ctx)
+	}
+	return nil
+}
+
+func (r groupReader) Stats() Stats {
+	return r.pg.Stats()
+}
+
+func (r groupReader) Done() <-chan struct{} {
+	return r.pg.Done()
+}
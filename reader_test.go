@@ -0,0 +1,48 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGroup_Reader(t *testing.T) {
+	pg := New()
+	r := pg.Reader()
+
+	if _, ok := FromContext(r.Context()); ok {
+		t.Fatal("Context should not expose the Group")
+	}
+	if _, ok := FromContext(context.WithValue(r.Context(), struct{}{}, 1)); ok {
+		t.Fatal("contexts derived from Context should not expose the Group")
+	}
+	if r.Err() != nil {
+		t.Fatalf("unexpected error: %v", r.Err())
+	}
+
+	release := make(chan struct{})
+	errExp := errors.New("error!")
+	GoAndForget(pg, func(ctx context.Context) error { <-release; return nil })
+	GoAndForget(pg, func(ctx context.Context) error { return errExp })
+
+	<-r.Context().Done()
+	if err := context.Cause(r.Context()); err != errExp {
+		t.Fatalf("Context should carry the cause of the cancellation: %v", err)
+	}
+	if err := r.Err(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := r.Stats(); s.Launched != 2 || s.Running != 1 || s.Completed != 1 || s.Failed != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	close(release)
+	pg.Wait()
+	<-r.Done()
+	if err := r.Err(); err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := r.Stats(); s.Running != 0 || s.Completed != 2 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
@@ -0,0 +1,58 @@
+package pgroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// promiseMap is a concurrency-safe map from names (or keys) to Promises, used by GoRegistered and GoOnce.
+type promiseMap struct {
+	mu sync.Mutex
+	ps map[string]any // of *Promise[T] for some T
+}
+
+func (m *promiseMap) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ps = nil
+}
+
+// GoRegistered is like Go, but also registers the returned Promise by name in the Group, so that it can be retrieved later by Lookup without threading it through deep call stacks.
+//
+// Names must be unique within the Group: it panics if the name is already registered (the task is not launched then), or if f is nil.
+func GoRegistered[T any](pg *Group, name string, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+
+	m := &pg.registry
+	m.mu.Lock()
+	if _, ok := m.ps[name]; ok {
+		m.mu.Unlock()
+		panic(fmt.Sprintf("pgroup: Promise named %q is already registered", name))
+	}
+	p := newPromise[T](pg)
+	if m.ps == nil {
+		m.ps = make(map[string]any)
+	}
+	m.ps[name] = p
+	m.mu.Unlock()
+
+	task, settle := promiseTask(p, f)
+	pg.launch(nil, task, settle)
+
+	return p
+}
+
+// Lookup returns the Promise registered by name with GoRegistered.
+// It reports false if no Promise is registered by the name, or the registered one has a result type other than T.
+func Lookup[T any](pg *Group, name string) (*Promise[T], bool) {
+	m := &pg.registry
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.ps[name].(*Promise[T])
+	return p, ok
+}
@@ -0,0 +1,29 @@
+package pgroup
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGoRegistered(t *testing.T) {
+	pg := New()
+
+	p := GoRegistered(pg, "answer", func(context.Context) (int, error) { return 42, nil })
+	assertPanics(t, `pgroup: Promise named "answer" is already registered`, func() {
+		GoRegistered(pg, "answer", func(context.Context) (int, error) { return 0, nil })
+	})
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := Lookup[int](pg, "answer")
+	if !ok || got != p || got.Get() != 42 {
+		t.Fatalf("unexpected lookup result: %v, %v", got, ok)
+	}
+	if _, ok := Lookup[string](pg, "answer"); ok {
+		t.Fatal("lookup with a different type should fail")
+	}
+	if _, ok := Lookup[int](pg, "missing"); ok {
+		t.Fatal("lookup of an unregistered name should fail")
+	}
+}
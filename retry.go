@@ -0,0 +1,55 @@
+package pgroup
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy specifies how GoRetry retries a failing task.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. 0 means no limit.
+	MaxAttempts int
+
+	// Backoff is the delay between attempts.
+	Backoff time.Duration
+
+	// MaxElapsed is the total time budget spanning all attempts, counted from the start of the first attempt. 0 means no limit.
+	// Attempts are not started once the budget is exhausted, and the context of the running attempt is canceled when the budget runs out.
+	MaxElapsed time.Duration
+}
+
+// GoRetry is like Go, but it retries the task according to the policy while it fails.
+// The returned Promise holds the result of the first successful attempt, or the error of the last attempt if all attempts failed.
+//
+// Retries stop as soon as the Group is canceled; in that case, the Promise holds the error of the cancellation (e.g. context.DeadlineExceeded for the deadline of the Group).
+// It panics if f is nil.
+func GoRetry[T any](pg *Group, policy RetryPolicy, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	return Go(pg, func(ctx context.Context) (T, error) {
+		taskCtx := ctx
+		if policy.MaxElapsed > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, policy.MaxElapsed)
+			defer cancel()
+		}
+
+		for attempt := 1; ; attempt++ {
+			res, err := f(ctx)
+			if err == nil {
+				return res, nil
+			}
+			if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+				return res, err
+			}
+			if Sleep(ctx, policy.Backoff) != nil {
+				if taskErr := taskCtx.Err(); taskErr != nil {
+					// the Group has been canceled (e.g. by its deadline), rather than the budget of retries has run out.
+					return res, taskErr
+				}
+				return res, err
+			}
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGoRetry(t *testing.T) {
+	pg := New()
+
+	var attempts atomic.Int32
+	p := GoRetry(pg, RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond}, func(context.Context) (int, error) {
+		if attempts.Add(1) < 3 {
+			return 0, errors.New("transient")
+		}
+		return 42, nil
+	})
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Get() != 42 || attempts.Load() != 3 {
+		t.Fatalf("unexpected result: %v after %d attempts", p.Get(), attempts.Load())
+	}
+}
+
+func TestGoRetry_maxAttempts(t *testing.T) {
+	pg := New()
+
+	var attempts atomic.Int32
+	errLast := errors.New("last")
+	GoRetry(pg, RetryPolicy{MaxAttempts: 3}, func(context.Context) (int, error) {
+		if attempts.Add(1) == 3 {
+			return 0, errLast
+		}
+		return 0, errors.New("transient")
+	})
+
+	if err := pg.Wait(); err != errLast {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Fatalf("unexpected number of attempts: %d", attempts.Load())
+	}
+}
+
+func TestGoRetry_maxElapsed(t *testing.T) {
+	pg := New()
+
+	var attempts atomic.Int32
+	errExp := errors.New("error!")
+	start := time.Now()
+	GoRetry(pg, RetryPolicy{Backoff: 10 * time.Millisecond, MaxElapsed: 35 * time.Millisecond}, func(context.Context) (int, error) {
+		attempts.Add(1)
+		return 0, errExp
+	})
+
+	err := pg.Wait()
+	elapsed := time.Since(start)
+	if err != errExp {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := attempts.Load(); n < 2 || n > 5 {
+		t.Fatalf("unexpected number of attempts: %d", n)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Fatalf("retries should stop within the budget: %v", elapsed)
+	}
+}
+
+func TestGoRetry_groupDeadline(t *testing.T) {
+	pg := New(WithTimeout(30 * time.Millisecond))
+
+	var attempts atomic.Int32
+	start := time.Now()
+	GoRetry(pg, RetryPolicy{Backoff: 5 * time.Millisecond}, func(context.Context) (int, error) {
+		attempts.Add(1)
+		return 0, errors.New("transient")
+	})
+	// the fallback and chained tasks are bounded by the same budget
+	fallback := GoOrElse(pg,
+		func(context.Context) (int, error) { return 0, errors.New("primary failed") },
+		func(ctx context.Context) (int, error) { return 0, Sleep(ctx, time.Second) },
+	)
+	chained := fallback.Then(func(ctx context.Context, v int) (int, error) { return v, nil })
+
+	err := pg.Wait()
+	elapsed := time.Since(start)
+	var de *DeadlineError
+	if !errors.As(err, &de) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed < 30*time.Millisecond || elapsed > 200*time.Millisecond {
+		t.Fatalf("retries should stop at the deadline of the Group: %v", elapsed)
+	}
+	if attempts.Load() < 2 {
+		t.Fatalf("task should be retried until the deadline: %d", attempts.Load())
+	}
+	if !errors.Is(fallback.err, context.DeadlineExceeded) || !errors.Is(chained.err, context.DeadlineExceeded) {
+		t.Fatalf("fallback and chained tasks should be bounded by the deadline: %v, %v", fallback.err, chained.err)
+	}
+}
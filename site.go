@@ -0,0 +1,73 @@
+package pgroup
+
+import (
+	"runtime"
+	"strings"
+)
+
+// WithCaptureLaunchSite makes the Group capture the stack of the call site launching each task (by Go, GoAndForget and so on), and attach it to any error the task produces as *LaunchSiteError.
+// It speeds up diagnosing which call site produced a failure in a big program.
+//
+// Capturing stacks has some overhead on every launch, so it's disabled by default.
+func WithCaptureLaunchSite() Option {
+	return func(pg *Group) {
+		pg.captureSites = true
+	}
+}
+
+// maxLaunchSiteDepth is the maximum number of frames captured as a launch site.
+const maxLaunchSiteDepth = 32
+
+// pkgFuncPrefix is the prefix of names of functions in this package.
+const pkgFuncPrefix = "github.com/jiftechnify/pgroup."
+
+// captureLaunchSite returns info with the stack of the launch site, if capturing is enabled. info is copied rather than modified.
+// Frames of this package are skipped, so that the stack starts from the call site outside of this package, whatever function launched the task.
+func (pg *Group) captureLaunchSite(info *taskInfo) *taskInfo {
+	if !pg.captureSites {
+		return info
+	}
+
+	pcs := make([]uintptr, maxLaunchSiteDepth)
+	// skip runtime.Callers and captureLaunchSite.
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+	for len(pcs) > 0 && isPkgFrame(pcs[0]) {
+		pcs = pcs[1:]
+	}
+
+	var withSite taskInfo
+	if info != nil {
+		withSite = *info
+	} else {
+		withSite.index = -1
+	}
+	withSite.site = pcs
+	return &withSite
+}
+
+// isPkgFrame reports whether the frame of pc is in a function of this package (excluding tests).
+func isPkgFrame(pc uintptr) bool {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return strings.HasPrefix(f.Function, pkgFuncPrefix) && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// LaunchSiteError is the error of a task launched on the Group configured by WithCaptureLaunchSite, carrying the stack of the call site which launched the task.
+type LaunchSiteError struct {
+	err  error
+	site []uintptr
+}
+
+func (e *LaunchSiteError) Error() string {
+	return e.err.Error()
+}
+
+func (e *LaunchSiteError) Unwrap() error {
+	return e.err
+}
+
+// LaunchSite returns the program counters of the stack of the call site which launched the task, starting from the caller of the function of this package launching it (e.g. Go, or any wrapper of it such as GoToken).
+// Use runtime.CallersFrames to translate them into functions, files and lines.
+func (e *LaunchSiteError) LaunchSite() []uintptr {
+	return e.site
+}
@@ -0,0 +1,71 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWithCaptureLaunchSite(t *testing.T) {
+	pg := New(WithCaptureLaunchSite(), WithCollectAll())
+
+	errExp := errors.New("error!")
+	p := Go(pg, func(context.Context) (int, error) { return 0, errExp })
+	GoAndForget(pg, func(context.Context) error { return nil })
+
+	err := pg.Wait()
+	if !errors.Is(err, errExp) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var lse *LaunchSiteError
+	if !errors.As(p.err, &lse) {
+		t.Fatalf("error should carry the launch site: %v", p.err)
+	}
+	frames := runtime.CallersFrames(lse.LaunchSite())
+	found := false
+	for {
+		f, more := frames.Next()
+		if strings.HasSuffix(f.Function, "TestWithCaptureLaunchSite") {
+			found = true
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	if !found {
+		t.Fatal("launch site should include the caller of Go")
+	}
+}
+
+func TestWithCaptureLaunchSite_entryPoints(t *testing.T) {
+	pg := New(WithCaptureLaunchSite(), WithCollectAll())
+
+	errExp := errors.New("error!")
+	fail := func(context.Context) (int, error) { return 0, errExp }
+	ps := map[string]*Promise[int]{
+		"Go": Go(pg, fail),
+	}
+	ps["GoToken"], _ = GoToken(pg, func(ctx context.Context, _ *Token) (int, error) { return fail(ctx) })
+	ps["GoWithCancelValue"] = GoWithCancelValue(pg, 0, fail)
+	sp := GoAndForgetP(pg, func(context.Context) error { return errExp })
+	pg.Wait()
+
+	sites := map[string]error{"GoAndForgetP": sp.err}
+	for name, p := range ps {
+		sites[name] = p.err
+	}
+	for name, err := range sites {
+		var lse *LaunchSiteError
+		if !errors.As(err, &lse) {
+			t.Fatalf("error should carry the launch site (%s): %v", name, err)
+		}
+		f, _ := runtime.CallersFrames(lse.LaunchSite()).Next()
+		if !strings.HasSuffix(f.Function, "TestWithCaptureLaunchSite_entryPoints") {
+			t.Fatalf("launch site should start from the call site (%s): %s", name, f.Function)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package pgroup
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// taskTimings aggregates durations of completed tasks.
+type taskTimings struct {
+	mu    sync.Mutex
+	min   time.Duration
+	max   time.Duration
+	total time.Duration
+	count int
+}
+
+func (tt *taskTimings) record(d time.Duration) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if tt.count == 0 || d < tt.min {
+		tt.min = d
+	}
+	if d > tt.max {
+		tt.max = d
+	}
+	tt.total += d
+	tt.count++
+}
+
+func (tt *taskTimings) reset() {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.min, tt.max, tt.total, tt.count = 0, 0, 0, 0
+}
+
+// TimingSummary returns a summary of durations of the tasks completed so far: the shortest and longest duration, the sum of all durations and the number of completed tasks.
+// All values are zero if no task has completed.
+//
+// It is typically called after Wait() returned, to find stragglers in a fan-out.
+func (pg *Group) TimingSummary() (min, max, total time.Duration, count int) {
+	tt := &pg.timings
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tt.min, tt.max, tt.total, tt.count
+}
+
+// ErrorsSoFar returns a copy of the errors collected so far in collect-all mode (see WithCollectAll), in the order they were reported.
+// Unlike Wait, it can be called at any time, even concurrently with running tasks, so that a monitoring goroutine can surface failures in real time.
+//
+// Errors dropped due to WithMaxCollectedErrors are not included. It always returns nil unless the Group is in collect-all mode.
+func (pg *Group) ErrorsSoFar() []error {
+	if !pg.collectAll {
+		return nil
+	}
+	return pg.errs.list()
+}
+
+// Stats is a snapshot of counters of tasks in a Group, returned from Group.Stats.
+type Stats struct {
+	// Launched is the number of tasks launched so far.
+	Launched int
+	// Running is the number of tasks launched but not completed yet.
+	Running int
+	// Completed is the number of tasks completed so far, whether succeeded or failed.
+	Completed int
+	// Failed is the number of completed tasks which returned an error reported to the Group.
+	Failed int
+	// Skipped is the number of tasks which were never run by the fail-fast optimization, because the parent context had already been canceled when the Group was created (see WithFailFastOnCanceledParent with FailFastSkip).
+	// It's not included in Launched. Tasks launched after the Group has been canceled for other reasons (e.g. a failure of a sibling) are still run by default, and counted in Launched.
+	Skipped int
+}
+
+// Stats returns a snapshot of counters of tasks in the Group. It can be called at any time, even while tasks are running.
+func (pg *Group) Stats() Stats {
+	// counters are read in the reverse order of their updates in the lifecycle of a task (launched, completed, then failed), so that the snapshot is consistent: Failed <= Completed <= Launched.
+	failed := int(pg.failed.Load())
+	_, _, _, completed := pg.TimingSummary()
+	launched := int(pg.launched.Load())
+	return Stats{
+		Launched:  launched,
+		Running:   launched - completed,
+		Completed: completed,
+		Failed:    failed,
+		Skipped:   int(pg.skipped.Load()),
+	}
+}
+
+// Progress returns the ratio of completed tasks to launched tasks so far, as a value between 0 and 1 (see Stats). It returns NaN if no task has been launched.
+// It is handy to wire into a gauge of metrics (e.g. a callback of a Prometheus gauge) for dashboards.
+func (pg *Group) Progress() float64 {
+	s := pg.Stats()
+	if s.Launched == 0 {
+		return math.NaN()
+	}
+	return float64(s.Completed) / float64(s.Launched)
+}
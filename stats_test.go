@@ -0,0 +1,182 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTimingSummary(t *testing.T) {
+	pg := New()
+
+	if min, max, total, count := pg.TimingSummary(); min != 0 || max != 0 || total != 0 || count != 0 {
+		t.Fatalf("summary should be zero before any task completes: %v %v %v %v", min, max, total, count)
+	}
+
+	GoAndForget(pg, delayedTask(100*time.Millisecond, func() error { return nil }))
+	GoAndForget(pg, delayedTask(200*time.Millisecond, func() error { return nil }))
+	GoAndForget(pg, delayedTask(300*time.Millisecond, func() error { return nil }))
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	min, max, total, count := pg.TimingSummary()
+	if count != 3 {
+		t.Fatalf("unexpected count (want: %v, got: %v)", 3, count)
+	}
+	if min < 100*time.Millisecond || min >= 200*time.Millisecond {
+		t.Fatalf("unexpected min: %v", min)
+	}
+	if max < 300*time.Millisecond {
+		t.Fatalf("unexpected max: %v", max)
+	}
+	if total < 600*time.Millisecond {
+		t.Fatalf("unexpected total: %v", total)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	pg := New()
+	if p := pg.Progress(); !math.IsNaN(p) {
+		t.Fatalf("progress should be NaN before any task is launched: %v", p)
+	}
+
+	release := make(chan struct{})
+	GoAndForget(pg, delayedTask(0, func() error { return nil }))
+	GoAndForget(pg, func(context.Context) error { <-release; return nil })
+
+	deadline := time.Now().Add(time.Second)
+	for pg.Progress() < 0.5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if p := pg.Progress(); p != 0.5 {
+		t.Fatalf("unexpected progress: %v", p)
+	}
+
+	close(release)
+	pg.Wait()
+	if p := pg.Progress(); p != 1 {
+		t.Fatalf("unexpected progress: %v", p)
+	}
+}
+
+func TestStats_consistent(t *testing.T) {
+	pg := New()
+
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				GoAndForget(pg, func(context.Context) error { return nil })
+			}
+		}
+	}()
+
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		s := pg.Stats()
+		if s.Running < 0 || s.Failed > s.Completed || s.Completed > s.Launched {
+			t.Fatalf("inconsistent stats: %+v", s)
+		}
+		if p := pg.Progress(); p > 1 {
+			t.Fatalf("progress should not exceed 1: %v", p)
+		}
+	}
+	close(stop)
+	<-stopped
+	pg.Wait()
+}
+
+func TestErrorsSoFar(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	errA, errB := errors.New("a"), errors.New("b")
+	release := make(chan struct{})
+	GoAndForget(pg, func(context.Context) error { return errA })
+	GoAndForget(pg, func(context.Context) error { <-release; return errB })
+
+	// the first error is visible before Wait
+	deadline := time.Now().Add(time.Second)
+	for len(pg.ErrorsSoFar()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if errs := pg.ErrorsSoFar(); len(errs) != 1 || errs[0] != errA {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	close(release)
+	pg.Wait()
+	if errs := pg.ErrorsSoFar(); len(errs) != 2 || errs[1] != errB {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if errs := New().ErrorsSoFar(); errs != nil {
+		t.Fatalf("errors should not be collected outside collect-all mode: %v", errs)
+	}
+}
+
+func TestStats_notSkippedByDefault(t *testing.T) {
+	t.Run("launching after Cancel", func(t *testing.T) {
+		pg := New()
+		pg.Cancel(nil)
+		GoAndForget(pg, func(ctx context.Context) error { return ctx.Err() })
+		if err := pg.Wait(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s := pg.Stats(); s.Skipped != 0 || s.Launched != 1 {
+			t.Fatalf("unexpected stats: %+v", s)
+		}
+	})
+
+	t.Run("launching after the timeout", func(t *testing.T) {
+		pg := New(WithTimeout(10 * time.Millisecond))
+		<-pg.ctx.Done()
+		GoAndForget(pg, func(ctx context.Context) error { return ctx.Err() })
+		var dErr *DeadlineError
+		if err := pg.Wait(); !errors.As(err, &dErr) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("chaining on a failed Promise", func(t *testing.T) {
+		errExp := errors.New("error!")
+
+		pg := New()
+		p := Go(pg, func(context.Context) (int, error) { return 0, errExp })
+		<-p.done
+
+		q := FlatMap(p, func(_ context.Context, v int) (int, error) { return v, nil })
+		r := Then(pg, p, func(_ context.Context, v int) (int, error) { return v, nil })
+		g := ThenGroup(p, func(_ context.Context, _ *Group, v int) (int, error) { return v, nil })
+		c := Catch(p, func(error) (int, error) { return 1, nil })
+		pg.Wait()
+
+		for _, err := range []error{q.err, r.err, g.err} {
+			if err != errExp {
+				t.Fatalf("chained Promise should short-circuit with the error of p: %v", err)
+			}
+		}
+		if c.err != nil || c.res != 1 {
+			t.Fatalf("Catch should recover from the error of p: %v, %v", c.res, c.err)
+		}
+	})
+
+	t.Run("GoWithCancelValue after Cancel", func(t *testing.T) {
+		pg := New()
+		pg.Cancel(nil)
+		p := GoWithCancelValue(pg, -1, func(ctx context.Context) (int, error) {
+			return 0, ctx.Err()
+		})
+		pg.Wait()
+		if p.err != nil || p.res != -1 {
+			t.Fatalf("canceled task should resolve with the cancel value: %v, %v", p.res, p.err)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package pgroup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// taskInfo is the identity of a task, which is injected to the context passed to the task.
+type taskInfo struct {
+	// name of the task. Empty for unnamed tasks.
+	name string
+	// index of the task in the submission by GoIndexed. -1 for non-indexed tasks.
+	index int
+
+	// class of the task and its weight, for scheduling by the limiter. See GoClass.
+	class  string
+	weight int
+
+	// stack of the launch site of the task. Captured only with WithCaptureLaunchSite.
+	site []uintptr
+}
+
+type taskInfoKey struct{}
+
+// TaskInfo returns the name and the index of the task which received ctx.
+// The name is set for tasks launched by GoNamed or GoAndForgetNamed, and the index is set for tasks launched by GoIndexed or GoRangeN; otherwise they are empty and -1 respectively.
+// The last return value reports whether ctx carries any of them.
+//
+// It lets tasks identify themselves in logs without closures capturing that data.
+func TaskInfo(ctx context.Context) (name string, index int, ok bool) {
+	info, ok := ctx.Value(taskInfoKey{}).(*taskInfo)
+	if !ok || (info.name == "" && info.index < 0) {
+		return "", -1, false
+	}
+	return info.name, info.index, true
+}
+
+// GoNamed is like Go, but launches the task with the name.
+// The name is available in the task via TaskInfo, and included in logs.
+func GoNamed[T any](pg *Group, name string, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	p := newPromise[T](pg)
+
+	task, settle := promiseTask(p, f)
+	pg.launch(&taskInfo{name: name, index: -1}, task, settle)
+
+	return p
+}
+
+// GoAndForgetNamed is like GoAndForget, but launches the task with the name.
+// The name is available in the task via TaskInfo, and included in logs.
+func GoAndForgetNamed(pg *Group, name string, f func(ctx context.Context) error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	pg.launch(&taskInfo{name: name, index: -1}, f, nil)
+}
+
+// NamedError is an error of a named task, reported by SideEffectErrors.
+type NamedError struct {
+	// Name is the name of the task.
+	Name string
+	// Err is the error returned from the task.
+	Err error
+}
+
+func (e NamedError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e NamedError) Unwrap() error {
+	return e.Err
+}
+
+// GoSideEffect is like GoAndForgetNamed, but an error of the task doesn't fail the Group nor cancel other tasks: it's recorded along with the name, and can be retrieved by SideEffectErrors.
+// It gives a tidy report of which side-effect tasks of a batch failed, without failing the whole batch.
+func GoSideEffect(pg *Group, name string, f func(ctx context.Context) error) {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	task := func(ctx context.Context) error {
+		if err := f(ctx); err != nil {
+			return &isolatedError{err: err}
+		}
+		return nil
+	}
+	settle := func(err error) {
+		if err != nil {
+			pg.sideEffects.add(NamedError{Name: name, Err: err})
+		}
+	}
+	pg.launch(&taskInfo{name: name, index: -1}, task, settle)
+}
+
+// SideEffectErrors returns errors of tasks launched by GoSideEffect recorded so far, in the order the tasks completed.
+// It is typically called after Wait() returned.
+func (pg *Group) SideEffectErrors() []NamedError {
+	return pg.sideEffects.list()
+}
+
+// namedErrorList is a concurrency-safe list of errors of named tasks.
+type namedErrorList struct {
+	mu   sync.Mutex
+	errs []NamedError
+}
+
+func (l *namedErrorList) add(err NamedError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs = append(l.errs, err)
+}
+
+func (l *namedErrorList) list() []NamedError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]NamedError(nil), l.errs...)
+}
+
+func (l *namedErrorList) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs = nil
+}
+
+// RunningTasks returns names of named tasks (launched by GoNamed or GoAndForgetNamed) which have started but not finished yet, in the order they started.
+// Unnamed tasks are not included.
+//
+// It is useful for debug endpoints showing what's in flight, and for diagnosing hangs.
+func (pg *Group) RunningTasks() []string {
+	return pg.running.names()
+}
+
+// taskRegistry tracks named tasks running in a Group.
+type taskRegistry struct {
+	mu      sync.Mutex
+	running map[uint64]string
+	lastID  uint64
+}
+
+// add registers a running task and returns its ID in the registry.
+func (r *taskRegistry) add(name string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running == nil {
+		r.running = make(map[uint64]string)
+	}
+	r.lastID++
+	r.running[r.lastID] = name
+	return r.lastID
+}
+
+func (r *taskRegistry) remove(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.running, id)
+}
+
+func (r *taskRegistry) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]uint64, 0, len(r.running))
+	for id := range r.running {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = r.running[id]
+	}
+	return names
+}
+
+// Sleep pauses the current goroutine for d, or until ctx is done. It returns ctx.Err() if ctx is done first, or nil otherwise.
+//
+// It is handy for tasks which wait for a while but should stop promptly on the cancellation of the Group.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskInfo(t *testing.T) {
+	pg := New()
+
+	named := GoNamed(pg, "fetch", func(ctx context.Context) (string, error) {
+		name, index, ok := TaskInfo(ctx)
+		if !ok || index != -1 {
+			t.Errorf("unexpected task info: %v, %v, %v", name, index, ok)
+		}
+		return name, nil
+	})
+	GoAndForgetNamed(pg, "store", func(ctx context.Context) error {
+		if name, _, ok := TaskInfo(ctx); !ok || name != "store" {
+			t.Errorf("unexpected task info: %v, %v", name, ok)
+		}
+		return nil
+	})
+	GoAndForget(pg, func(ctx context.Context) error {
+		if _, index, ok := TaskInfo(ctx); ok || index != -1 {
+			t.Errorf("anonymous tasks should not have task info: %v, %v", index, ok)
+		}
+		return nil
+	})
+	indexed := GoIndexed(pg,
+		func(ctx context.Context) (int, error) { _, i, _ := TaskInfo(ctx); return i, nil },
+		func(ctx context.Context) (int, error) { _, i, _ := TaskInfo(ctx); return i, nil },
+	)
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if named.Get() != "fetch" {
+		t.Fatalf("unexpected name: %v", named.Get())
+	}
+	for r := range indexed {
+		if r.Value != r.Index {
+			t.Fatalf("unexpected index in task (want: %v, got: %v)", r.Index, r.Value)
+		}
+	}
+}
+
+func TestRunningTasks(t *testing.T) {
+	pg := New()
+
+	release := map[string]chan struct{}{
+		"a": make(chan struct{}),
+		"b": make(chan struct{}),
+	}
+	started := make(chan struct{}, 3)
+	for _, name := range []string{"a", "b"} {
+		name := name
+		GoAndForgetNamed(pg, name, func(ctx context.Context) error {
+			started <- struct{}{}
+			<-release[name]
+			return nil
+		})
+		<-started
+	}
+	unnamed := make(chan struct{})
+	GoAndForget(pg, func(ctx context.Context) error { started <- struct{}{}; <-unnamed; return nil })
+	<-started
+
+	assertEvents(t, []string{"a", "b"}, pg.RunningTasks())
+
+	close(release["a"])
+	deadline := time.Now().Add(time.Second)
+	for len(pg.RunningTasks()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEvents(t, []string{"b"}, pg.RunningTasks())
+
+	close(release["b"])
+	close(unnamed)
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(pg.RunningTasks()); n != 0 {
+		t.Fatalf("no task should be running after Wait: %d", n)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), 10*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Second); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("Sleep should return soon after the cancellation")
+	}
+}
+
+func TestGoSideEffect(t *testing.T) {
+	pg := New()
+
+	errA := errors.New("write a failed")
+	GoSideEffect(pg, "a", func(context.Context) error { return errA })
+	GoSideEffect(pg, "b", delayedTask(20*time.Millisecond, func() error { return nil }))
+	GoSideEffect(pg, "c", delayedTask(10*time.Millisecond, func() error { return errors.New("write c failed") }))
+
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("errors of side-effects should not fail the Group: %v", err)
+	}
+	errs := pg.SideEffectErrors()
+	if len(errs) != 2 || errs[0].Name != "a" || errs[1].Name != "c" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !errors.Is(errs[0], errA) || errs[0].Error() != "a: write a failed" {
+		t.Fatalf("unexpected error: %v", errs[0])
+	}
+}
@@ -0,0 +1,164 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WithTimeout sets a hard budget of wall-clock time on the whole Group: the Group's context times out after d from the creation of the Group, as if the parent context had the deadline.
+// Since contexts of all tasks are derived from the Group's context, the budget bounds everything running on the Group transitively, including retries by GoRetry, fallbacks by GoOrElse and tasks chained by Then.
+//
+// If the Group fails due to the timeout, Wait returns a *DeadlineError.
+func WithTimeout(d time.Duration) Option {
+	return func(pg *Group) {
+		pg.timeout = d
+	}
+}
+
+// WithTaskDeadline makes every task in the Group run with a context which has the absolute deadline t (see context.WithDeadline), for tasks which must finish by a fixed clock time.
+// Unlike WithTimeout, the deadline applies to contexts of tasks rather than the Group's context: tasks exceeding it fail with context.DeadlineExceeded, which is reported to the Group as usual.
+func WithTaskDeadline(t time.Time) Option {
+	return func(pg *Group) {
+		pg.taskDeadline = t
+	}
+}
+
+// GoWithTimeout is like Go, but the task is given a context which times out after d.
+// The timeout is counted from the call to GoWithTimeout, so it includes the time spent waiting for the limiter (see SetLimit).
+//
+// The deadline of the task is available via Promise.Deadline.
+func GoWithTimeout[T any](pg *Group, d time.Duration, f func(ctx context.Context) (T, error)) *Promise[T] {
+	if f == nil {
+		panic(nilTaskMsg)
+	}
+	p := newPromise[T](pg)
+	p.deadline = time.Now().Add(d)
+
+	task, settle := promiseTask(p, func(ctx context.Context) (T, error) {
+		ctx, cancel := context.WithDeadline(ctx, p.deadline)
+		defer cancel()
+		return f(ctx)
+	})
+	pg.launch(nil, task, settle)
+
+	return p
+}
+
+// Deadline returns the deadline of the task corresponding to p, if it's launched with a timeout (e.g. by GoWithTimeout).
+// The second return value reports whether the deadline is set.
+//
+// It is available before the task resolves, and helps consumers decide how long to wait for the result.
+func (p *Promise[T]) Deadline() (time.Time, bool) {
+	return p.deadline, !p.deadline.IsZero()
+}
+
+// GetTimeout blocks up to d until p resolves, and returns its result value. It reports false if p hasn't resolved in time.
+// It bounds the patience of the consumer, independently of the deadline of the task itself; it's useful to poll individual Promises without Wait-ing on the Group.
+//
+// Like Get, the value is the zero value if the task failed.
+func (p *Promise[T]) GetTimeout(d time.Duration) (T, bool) {
+	// resolved Promises are returned even if d <= 0.
+	select {
+	case <-p.done:
+		return p.res, true
+	default:
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-p.done:
+		return p.res, true
+	case <-t.C:
+		var zero T
+		return zero, false
+	}
+}
+
+// WaitTimeout is like Wait, but it gives up waiting after d and returns context.DeadlineExceeded if tasks are still running.
+// In that case, the Group is not canceled and tasks keep running; the caller may call Wait (or Cancel) afterwards.
+func (pg *Group) WaitTimeout(d time.Duration) error {
+	err, _ := pg.WaitTimeoutDetailed(d)
+	return err
+}
+
+// WaitTimeoutDetailed is like WaitTimeout, but it also returns names of named tasks (see GoNamed) which were still running when the timeout fired, to pinpoint which tasks caused the timeout.
+// The list is nil if all tasks have completed in time.
+func (pg *Group) WaitTimeoutDetailed(d time.Duration) (error, []string) {
+	if !pg.waitWithin(d) {
+		return context.DeadlineExceeded, pg.RunningTasks()
+	}
+	return pg.waitErr(), nil
+}
+
+// waitWithin waits for all tasks to complete up to d, and finishes the Group if they have completed.
+// It reports whether all tasks have completed in time.
+func (pg *Group) waitWithin(d time.Duration) bool {
+	if !pg.waitTasksWithin(d) {
+		return false
+	}
+	pg.finish()
+	return true
+}
+
+// waitTasksWithin waits for all tasks to complete up to d, without finishing the Group. It reports whether all tasks have completed in time.
+func (pg *Group) waitTasksWithin(d time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		pg.waitTasks()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// ErrWaitTimeout is the error returned from Wait of the Group configured by WithWaitTimeout, if tasks haven't completed in time.
+var ErrWaitTimeout = errors.New("pgroup: timed out waiting for tasks")
+
+// WithWaitTimeout sets up a watchdog on Wait: if tasks haven't completed within d after Wait is called, the Group is canceled with ErrWaitTimeout and Wait returns an error wrapping ErrWaitTimeout, instead of blocking forever on a misbehaving task which ignores cancellation.
+// The error reports names of named tasks (see GoNamed) still running at that time.
+//
+// Tasks still running are left behind; hooks registered by OnDone are not run in that case.
+// The watchdog also guards the variants of Wait (e.g. WaitIgnoreCancel and WaitNoCancel). By default, Wait blocks until all tasks complete.
+func WithWaitTimeout(d time.Duration) Option {
+	return func(pg *Group) {
+		pg.waitTimeout = d
+	}
+}
+
+// waitGuarded waits for all tasks to complete under the watchdog set by WithWaitTimeout (if any), then finishes the Group if finish is true.
+// It returns the error of the watchdog if tasks haven't completed in time, and nil otherwise.
+func (pg *Group) waitGuarded(finish bool) error {
+	if pg.waitTimeout <= 0 {
+		pg.waitTasks()
+	} else if !pg.waitTasksWithin(pg.waitTimeout) {
+		return pg.watchdogErr()
+	}
+	if finish {
+		pg.finish()
+	}
+	return nil
+}
+
+// watchdogErr cancels the Group whose tasks haven't completed within the timeout set by WithWaitTimeout, and returns the error reporting tasks still running.
+func (pg *Group) watchdogErr() error {
+	pg.cancelSelf(ErrWaitTimeout)
+
+	running := pg.RunningTasks()
+	if len(running) == 0 {
+		return ErrWaitTimeout
+	}
+	return fmt.Errorf("%w (still running: %s)", ErrWaitTimeout, strings.Join(running, ", "))
+}
@@ -0,0 +1,210 @@
+package pgroup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoWithTimeout(t *testing.T) {
+	pg := New(WithCollectAll())
+
+	start := time.Now()
+	slow := GoWithTimeout(pg, 100*time.Millisecond, delayedResultTask(time.Second, func() (int, error) { return 1, nil }))
+	fast := GoWithTimeout(pg, time.Second, delayedResultTask(10*time.Millisecond, func() (int, error) { return 2, nil }))
+	plain := Go(pg, func(ctx context.Context) (int, error) { return 3, nil })
+
+	d, ok := slow.Deadline()
+	if !ok || d.Before(start.Add(100*time.Millisecond)) || d.After(time.Now().Add(100*time.Millisecond)) {
+		t.Fatalf("unexpected deadline: %v, %v", d, ok)
+	}
+	if _, ok := plain.Deadline(); ok {
+		t.Fatal("deadline should not be set for tasks without timeout")
+	}
+
+	err := pg.Wait()
+	if err == nil || slow.err != context.DeadlineExceeded {
+		t.Fatalf("slow task should time out: %v, %v", err, slow.err)
+	}
+	if fast.Get() != 2 || plain.Get() != 3 {
+		t.Fatalf("unexpected results: %v, %v", fast.Get(), plain.Get())
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("slow task should be canceled on timeout")
+	}
+}
+
+func TestWaitTimeoutDetailed(t *testing.T) {
+	pg := New()
+
+	release := make(chan struct{})
+	GoAndForgetNamed(pg, "fast", delayedTask(10*time.Millisecond, func() error { return nil }))
+	GoAndForgetNamed(pg, "straggler", func(ctx context.Context) error { <-release; return nil })
+
+	err, stragglers := pg.WaitTimeoutDetailed(100 * time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEvents(t, []string{"straggler"}, stragglers)
+
+	// the Group is still usable after the timeout.
+	close(release)
+	if err := pg.WaitTimeout(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithWaitTimeout(t *testing.T) {
+	pg := New(WithWaitTimeout(20 * time.Millisecond))
+
+	leaked := make(chan struct{})
+	defer close(leaked)
+	GoNamed(pg, "stubborn", func(context.Context) (int, error) {
+		// ignores cancellation
+		<-leaked
+		return 0, nil
+	})
+	GoNamed(pg, "polite", delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }))
+
+	err := pg.Wait()
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "stubborn") || strings.Contains(err.Error(), "polite") {
+		t.Fatalf("error should report the stuck task: %v", err)
+	}
+	if !errors.Is(context.Cause(pg.ctx), ErrWaitTimeout) {
+		t.Fatal("Group should be canceled on timeout")
+	}
+}
+
+func TestWithWaitTimeout_variants(t *testing.T) {
+	variants := map[string]func(*Group) error{
+		"WaitIgnoreCancel": (*Group).WaitIgnoreCancel,
+		"WaitNoCancel":     (*Group).WaitNoCancel,
+	}
+	for name, wait := range variants {
+		pg := New(WithWaitTimeout(20 * time.Millisecond))
+
+		leaked := make(chan struct{})
+		GoNamed(pg, "stubborn", func(context.Context) (int, error) {
+			<-leaked
+			return 0, nil
+		})
+
+		done := make(chan error, 1)
+		go func() { done <- wait(pg) }()
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrWaitTimeout) {
+				t.Fatalf("unexpected error of %s: %v", name, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s should be guarded by the watchdog", name)
+		}
+		close(leaked)
+	}
+}
+
+func TestWithWaitTimeout_inTime(t *testing.T) {
+	pg := New(WithWaitTimeout(time.Second))
+
+	GoAndForget(pg, delayedTask(10*time.Millisecond, func() error { return nil }))
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeadlineError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	pg := WithContext(ctx)
+	GoAndForget(pg, func(context.Context) error {
+		// ignores cancellation for a while
+		time.Sleep(40 * time.Millisecond)
+		return context.DeadlineExceeded
+	})
+
+	err := pg.Wait()
+	var de *DeadlineError
+	if !errors.As(err, &de) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, _ := ctx.Deadline(); !de.Deadline().Equal(want) {
+		t.Fatalf("unexpected deadline: %v", de.Deadline())
+	}
+	if o := de.Overrun(); o < 10*time.Millisecond || o > 500*time.Millisecond {
+		t.Fatalf("unexpected overrun: %v", o)
+	}
+
+	// timeouts of tasks are not attributed to the deadline of the Group
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	pg = WithContext(ctx)
+	GoWithTimeout(pg, time.Millisecond, delayedResultTask(time.Second, func() (int, error) { return 0, nil }))
+	if err := pg.Wait(); errors.As(err, &de) || err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPromise_GetTimeout(t *testing.T) {
+	pg := New()
+
+	fast := Go(pg, delayedResultTask(10*time.Millisecond, func() (int, error) { return 1, nil }))
+	slow := Go(pg, delayedResultTask(200*time.Millisecond, func() (int, error) { return 2, nil }))
+
+	if v, ok := fast.GetTimeout(time.Second); !ok || v != 1 {
+		t.Fatalf("unexpected result: %v, %v", v, ok)
+	}
+	if v, ok := slow.GetTimeout(10 * time.Millisecond); ok || v != 0 {
+		t.Fatalf("GetTimeout should time out: %v, %v", v, ok)
+	}
+	if err := pg.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := slow.GetTimeout(0); !ok || v != 2 {
+		t.Fatalf("resolved Promise should be returned immediately: %v, %v", v, ok)
+	}
+}
+
+func TestWithTaskDeadline(t *testing.T) {
+	deadline := time.Now().Add(50 * time.Millisecond)
+	pg := New(WithTaskDeadline(deadline), WithCollectAll())
+
+	p := Go(pg, func(ctx context.Context) (time.Time, error) {
+		d, _ := ctx.Deadline()
+		return d, nil
+	})
+	slow := Go(pg, delayedResultTask(time.Second, func() (int, error) { return 1, nil }))
+
+	err := pg.Wait()
+	if !errors.Is(err, context.DeadlineExceeded) || slow.err != context.DeadlineExceeded {
+		t.Fatalf("tasks exceeding the deadline should fail: %v, %v", err, slow.err)
+	}
+	if !p.Get().Equal(deadline) {
+		t.Fatalf("unexpected deadline of the task: %v", p.Get())
+	}
+	if _, ok := pg.ctx.Deadline(); ok {
+		t.Fatal("the Group's context should not have the deadline")
+	}
+}
+
+func TestDeadlineError_identical(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	pg := WithContext(ctx)
+	GoAndForget(pg, delayedTask(time.Second, func() error { return nil }))
+
+	err := pg.Wait()
+	var de *DeadlineError
+	if !errors.As(err, &de) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err2 := pg.Wait(); err2 != err {
+		t.Fatalf("repeated Waits should return the identical error: %v, %v", err, err2)
+	}
+}